@@ -18,6 +18,9 @@ type (
 type (
 	IntLit struct {
 		Value int `json:"value"`
+		// Raw is the literal in the source, e.g. 0x1f.
+		// String returns the value in decimal if empty.
+		Raw string `json:"raw,omitempty"`
 	}
 
 	FloatLit struct {
@@ -29,6 +32,11 @@ type (
 	}
 )
 
-func (s *IntLit) String() string    { return strconv.Itoa(s.Value) }
+func (s *IntLit) String() string {
+	if s.Raw != "" {
+		return s.Raw
+	}
+	return strconv.Itoa(s.Value)
+}
 func (s *FloatLit) String() string  { return fmt.Sprint(s.Value) }
 func (s *StringLit) String() string { return fmt.Sprintf(`"%s"`, s.Value) }
@@ -18,6 +18,7 @@ type (
 type (
 	Statement struct {
 		SelectSection  *SelectSection  `json:"select,omitempty"`
+		PruneSection   *PruneSection   `json:"prune,omitempty"`
 		WhereSection   *WhereSection   `json:"where,omitempty"`
 		HavingSection  *HavingSection  `json:"having,omitempty"`
 		GroupBySection *GroupBySection `json:"group_by,omitempty"`
@@ -29,6 +30,9 @@ type (
 func (s *Statement) String() string {
 	b := buf.NewStrings()
 	b.Add(s.SelectSection.String())
+	if s.PruneSection != nil {
+		b.Add(s.PruneSection.String())
+	}
 	if s.WhereSection != nil {
 		b.Add(s.WhereSection.String())
 	}
@@ -14,9 +14,9 @@ type (
 	}
 )
 
-//go:generate marker -method IsSection -type SelectSection,WhereSection,HavingSection,GroupBySection,OrderBySection,LimitSection -output section_marker_generated.go
+//go:generate marker -method IsSection -type SelectSection,PruneSection,WhereSection,HavingSection,GroupBySection,OrderBySection,LimitSection -output section_marker_generated.go
 
-//go:generate marker -method IsNode -type SelectSection,SelectTerms,SelectTerm,SelectOption,SelectTarget,WhereSection,WhereCondition,GroupBySection,GroupByTerms,GroupByTerm,HavingSection,OrderBySection,OrderByTerms,OrderByTerm,OrderByTermOption,LimitSection -output section_node_marker_generated.go
+//go:generate marker -method IsNode -type SelectSection,SelectTerms,SelectTerm,SelectOption,SelectTarget,PruneSection,WhereSection,WhereCondition,GroupBySection,GroupByTerms,GroupByTerm,HavingSection,OrderBySection,OrderByTerms,OrderByTerm,OrderByTermOption,LimitSection -output section_node_marker_generated.go
 
 type (
 	SelectSection struct {
@@ -37,6 +37,10 @@ type (
 		Expr Expr `json:"expr,omitempty"`
 	}
 
+	PruneSection struct {
+		Condition *WhereCondition `json:"condition,omitempty"`
+	}
+
 	WhereSection struct {
 		Condition *WhereCondition `json:"condition,omitempty"`
 	}
@@ -118,6 +122,10 @@ func (s *SelectTarget) String() string {
 	return s.Expr.String()
 }
 
+func (s *PruneSection) String() string {
+	return fmt.Sprintf("prune where %s", s.Condition)
+}
+
 func (s *WhereSection) String() string {
 	return fmt.Sprintf("where %s", s.Condition)
 }
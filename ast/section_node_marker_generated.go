@@ -1,4 +1,4 @@
-// Code generated by "marker -method IsNode -type SelectSection,SelectTerms,SelectTerm,SelectOption,SelectTarget,WhereSection,WhereCondition,GroupBySection,GroupByTerms,GroupByTerm,HavingSection,OrderBySection,OrderByTerms,OrderByTerm,OrderByTermOption,LimitSection -output section_node_marker_generated.go"; DO NOT EDIT.
+// Code generated by "marker -method IsNode -type SelectSection,SelectTerms,SelectTerm,SelectOption,SelectTarget,PruneSection,WhereSection,WhereCondition,GroupBySection,GroupByTerms,GroupByTerm,HavingSection,OrderBySection,OrderByTerms,OrderByTerm,OrderByTermOption,LimitSection -output section_node_marker_generated.go"; DO NOT EDIT.
 
 package ast
 
@@ -7,6 +7,7 @@ func (*SelectTerms) IsNode()       {}
 func (*SelectTerm) IsNode()        {}
 func (*SelectOption) IsNode()      {}
 func (*SelectTarget) IsNode()      {}
+func (*PruneSection) IsNode()      {}
 func (*WhereSection) IsNode()      {}
 func (*WhereCondition) IsNode()    {}
 func (*GroupBySection) IsNode()    {}
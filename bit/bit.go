@@ -89,3 +89,9 @@ func FromBinaryString(v string) (int, error) {
 
 // ToBinaryString converts an integer into a binary digits.
 func ToBinaryString(v int) string { return strconv.FormatInt(int64(v), 2) }
+
+// ToOctalString converts an integer into an octal digits.
+func ToOctalString(v int) string { return strconv.FormatInt(int64(v), 8) }
+
+// ToHexString converts an integer into a hex digits.
+func ToHexString(v int) string { return strconv.FormatInt(int64(v), 16) }
@@ -0,0 +1,495 @@
+package calc
+
+import (
+	"github.com/berquerant/dql/arithmetic"
+	"github.com/berquerant/dql/ast"
+	"github.com/berquerant/dql/bit"
+	"github.com/berquerant/dql/compare"
+	"github.com/berquerant/dql/data"
+	"github.com/berquerant/dql/env"
+	"github.com/berquerant/dql/errors"
+	"github.com/berquerant/dql/function"
+	"github.com/berquerant/dql/logger"
+)
+
+type (
+	// Columns resolves the names of the columns to the slots of Frame.
+	Columns interface {
+		Slot(name string) (int, bool)
+	}
+
+	// Frame is the values of the columns to evaluate a Program.
+	Frame interface {
+		// Data returns the value of the column.
+		// Returns false if the column has no single value, e.g. grouped.
+		Data(slot int) (data.Data, bool)
+		// DataList returns the values of the column to aggregate.
+		// Returns false if the column is not grouped.
+		DataList(slot int) ([]data.Data, bool)
+	}
+
+	// Program is a compiled expr.
+	// A Program is not safe for concurrent use.
+	Program func(frame Frame) (data.Data, error)
+
+	// Compiler compiles an expr into a Program.
+	Compiler interface {
+		Compile(expr ast.Expr) Program
+	}
+)
+
+// NewCompiler returns a new Compiler.
+// The idents are resolved to the columns first, and then to the values in env, e.g. aliases.
+// The functions are looked up once on compilation.
+// The errors like unknown idents are reported when the Program is evaluated, as Calculator.
+func NewCompiler(
+	comparer compare.Comparer,
+	artCalculator arithmetic.Calculator,
+	bitCalculator bit.Calculator,
+	funcCaller function.Caller,
+	columns Columns,
+	env env.Map,
+) Compiler {
+	return &compiler{
+		calculator: &calculator{
+			comparer:      comparer,
+			artCalculator: artCalculator,
+			bitCalculator: bitCalculator,
+			funcCaller:    funcCaller,
+		},
+		columns: columns,
+		env:     env,
+	}
+}
+
+type compiler struct {
+	*calculator
+	columns Columns
+	env     env.Map
+	// aliases are the idents being compiled, to detect cyclic references
+	aliases map[string]bool
+	// aggregation is the state of the aggregation being compiled
+	aggregation *aggregationState
+}
+
+// aggregationState is the state of an aggregation function call.
+type aggregationState struct {
+	// index is the index of the row being evaluated
+	index int
+	// target is the column to aggregate
+	target     string
+	targetSlot int
+	// err is the error found on compilation
+	err error
+}
+
+func (s *compiler) Compile(expr ast.Expr) Program {
+	p := s.compile(expr)
+	return func(frame Frame) (data.Data, error) {
+		d, err := p(frame)
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot calculate %s", expr)
+		}
+		return d, nil
+	}
+}
+
+// fail returns a Program that always returns err.
+func fail(err error) Program {
+	return func(_ Frame) (data.Data, error) { return nil, err }
+}
+
+// constant returns a Program that always returns v.
+func constant(v data.Data) Program {
+	return func(_ Frame) (data.Data, error) { return v, nil }
+}
+
+func (s *compiler) compile(expr ast.Expr) Program {
+	switch expr := expr.(type) {
+	case *ast.OrExpr, *ast.AndExpr, *ast.XorExpr, *ast.NotExpr:
+		return s.compileLogicalOperator(expr)
+	case ast.BoolPrimary:
+		return s.compileBoolPrimary(expr)
+	case ast.Predicate:
+		return s.compilePredicate(expr)
+	case ast.BitExpr:
+		return s.compileBitExpr(expr)
+	case ast.SimpleExpr:
+		return s.compileSimpleExpr(expr)
+	default:
+		return fail(errors.Wrap(ErrUnknownExpr, "%s", logger.JSON(expr)))
+	}
+}
+
+func (s *compiler) compileSimpleExpr(expr ast.SimpleExpr) Program {
+	switch expr := expr.(type) {
+	case *ast.SimpleExprPrefixOp:
+		var (
+			op  = expr.Op
+			arg = s.compile(expr.Expr)
+		)
+		return func(frame Frame) (data.Data, error) {
+			v, err := arg(frame)
+			if err != nil {
+				return nil, errors.Wrap(err, "arg %s", expr.Expr)
+			}
+			return s.prefixOp(op, v)
+		}
+	case *ast.SimpleExprLit:
+		v, err := s.dataLit(expr.Lit)
+		if err != nil {
+			return fail(err)
+		}
+		return constant(v)
+	case *ast.Ident:
+		if s.aggregation != nil {
+			return s.compileIdentAggregation(expr)
+		}
+		return s.compileIdentNormal(expr)
+	case *ast.FunctionCall:
+		f, exist := s.funcCaller.Func(expr.FunctionName.Value)
+		if !exist {
+			return fail(errors.Wrap(ErrUnknownExpr, "function call function %s not found", expr.FunctionName.Value))
+		}
+		if _, ok := f.(function.Aggregation); ok {
+			if s.aggregation != nil {
+				return fail(errors.Wrap(ErrUnknownExpr, "aggregation cannot be nested"))
+			}
+			return s.compileFunctionCallAggregation(expr, f)
+		}
+		return s.compileFunctionCallNormal(expr, f)
+	case *ast.SimpleExprExpr:
+		return s.compile(expr.Expr)
+	default:
+		return fail(errors.Wrap(ErrUnknownExpr, "simple expr %s", logger.JSON(expr)))
+	}
+}
+
+func (s *compiler) compileIdentNormal(expr *ast.Ident) Program {
+	name := expr.Value
+	if slot, ok := s.columns.Slot(name); ok {
+		return func(frame Frame) (data.Data, error) {
+			if v, ok := frame.Data(slot); ok {
+				return v, nil
+			}
+			return nil, errors.Wrap(ErrUnknownExpr, "unknown ident %s", name)
+		}
+	}
+	v, ok := s.env.Get(name)
+	if !ok {
+		return fail(errors.Wrap(ErrUnknownExpr, "cannot find ident %s", name))
+	}
+	switch v.Type() {
+	case env.TypeData:
+		return constant(v.Data())
+	case env.TypeExpr:
+		if s.aliases[name] {
+			return fail(errors.Wrap(ErrUnknownExpr, "cyclic reference of ident %s", name))
+		}
+		if s.aliases == nil {
+			s.aliases = map[string]bool{}
+		}
+		s.aliases[name] = true
+		p := s.compile(v.Expr())
+		delete(s.aliases, name)
+		return func(frame Frame) (data.Data, error) {
+			d, err := p(frame)
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to calc ident %s %s", name, v.Expr())
+			}
+			return d, nil
+		}
+	default:
+		return fail(errors.Wrap(ErrUnknownExpr, "unknown ident %s %s", name, logger.JSON(v)))
+	}
+}
+
+func (s *compiler) compileIdentAggregation(expr *ast.Ident) Program {
+	name := expr.Value
+	slot, ok := s.columns.Slot(name)
+	if !ok {
+		if _, ok := s.env.Get(name); !ok {
+			return fail(errors.Wrap(ErrUnknownExpr, "cannot find ident %s", name))
+		}
+		return fail(errors.Wrap(ErrTypeMismatch, "cannot find data list %s", name))
+	}
+	state := s.aggregation
+	if state.target == "" {
+		state.target = name
+		state.targetSlot = slot
+	} else if state.target != name && state.err == nil {
+		state.err = errors.Wrap(ErrUnknownExpr, "aggregation cannot depend on multiple columns %s %s",
+			state.target, name)
+	}
+	return func(frame Frame) (data.Data, error) {
+		list, ok := frame.DataList(slot)
+		if !ok {
+			return nil, errors.Wrap(ErrTypeMismatch, "cannot find data list %s", name)
+		}
+		if state.index < 0 || state.index >= len(list) {
+			return nil, errors.Wrap(ErrUnknownExpr, "out of index %d in %s", state.index, name)
+		}
+		return list[state.index], nil
+	}
+}
+
+func (s *compiler) compileFunctionCallAggregation(expr *ast.FunctionCall, f function.Function) Program {
+	name := expr.FunctionName.Value
+	if len(expr.Arguments.Exprs) != 1 {
+		return fail(errors.Wrap(ErrUnknownExpr,
+			"number of aggregation function arguments must be 1 but got %d", len(expr.Arguments.Exprs)))
+	}
+	state := &aggregationState{}
+	s.aggregation = state
+	arg := s.compile(expr.Arguments.Exprs[0])
+	s.aggregation = nil
+	if state.err != nil {
+		return fail(state.err)
+	}
+	if state.target == "" {
+		return fail(errors.Wrap(ErrUnknownExpr, "aggregation %s requires a column", name))
+	}
+	return func(frame Frame) (data.Data, error) {
+		list, ok := frame.DataList(state.targetSlot)
+		if !ok {
+			return nil, errors.Wrap(ErrTypeMismatch, "cannot find data list %s", state.target)
+		}
+		if len(list) == 0 {
+			return nil, errors.Wrap(ErrUnknownExpr, "out of index 0 in %s", state.target)
+		}
+		args := make([]data.Data, len(list))
+		for i := range list {
+			state.index = i
+			v, err := arg(frame)
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to calc %s[%d] on aggregation %s", state.target, i, name)
+			}
+			args[i] = v
+		}
+		r, err := f.Call(args...)
+		if err != nil {
+			return nil, errors.Wrap(err, "function call %s from function %s", expr, name)
+		}
+		return r, nil
+	}
+}
+
+func (s *compiler) compileFunctionCallNormal(expr *ast.FunctionCall, f function.Function) Program {
+	var (
+		name = expr.FunctionName.Value
+		args = s.compileList(expr.Arguments.Exprs)
+	)
+	return func(frame Frame) (data.Data, error) {
+		xs := make([]data.Data, len(args))
+		for i, a := range args {
+			v, err := a(frame)
+			if err != nil {
+				return nil, errors.Wrap(err, "function call %s args[%d]", expr, i)
+			}
+			xs[i] = v
+		}
+		r, err := f.Call(xs...)
+		if err != nil {
+			return nil, errors.Wrap(err, "function call %s from function %s", expr, name)
+		}
+		return r, nil
+	}
+}
+
+func (s *compiler) compileList(exprs []ast.Expr) []Program {
+	ps := make([]Program, len(exprs))
+	for i, x := range exprs {
+		ps[i] = s.compile(x)
+	}
+	return ps
+}
+
+func (s *compiler) compileBitExpr(expr ast.BitExpr) Program {
+	switch expr := expr.(type) {
+	case *ast.BitExprSimpleExpr:
+		return s.compile(expr.Expr)
+	case *ast.BitExprBitOp:
+		op := expr.Op
+		return s.compileBinaryOp(expr, func(left, right data.Data) (data.Data, error) {
+			return s.dataBitOp(op, left, right)
+		})
+	case *ast.BitExprArtOp:
+		op := expr.Op
+		return s.compileBinaryOp(expr, func(left, right data.Data) (data.Data, error) {
+			return s.dataArithmeticOp(op, left, right)
+		})
+	default:
+		return fail(errors.Wrap(ErrUnknownExpr, "%s", logger.JSON(expr)))
+	}
+}
+
+// compileBinaryOp returns a Program that evaluates the args of expr and applies f.
+func (s *compiler) compileBinaryOp(expr ast.BinaryOp, f func(left, right data.Data) (data.Data, error)) Program {
+	var (
+		left  = s.compile(expr.LeftArg())
+		right = s.compile(expr.RightArg())
+	)
+	return func(frame Frame) (data.Data, error) {
+		l, err := left(frame)
+		if err != nil {
+			return nil, errors.Wrap(err, "left arg %s", expr.LeftArg())
+		}
+		r, err := right(frame)
+		if err != nil {
+			return nil, errors.Wrap(err, "right arg %s", expr.RightArg())
+		}
+		return f(l, r)
+	}
+}
+
+func (s *compiler) compilePredicate(expr ast.Predicate) Program {
+	var p Program
+	switch expr := expr.(type) {
+	case *ast.PredicateIn:
+		var (
+			target = s.compile(expr.Target)
+			list   = s.compileList(expr.List.Exprs)
+		)
+		p = func(frame Frame) (data.Data, error) {
+			t, err := target(frame)
+			if err != nil {
+				return nil, errors.Wrap(err, "target %s", expr.Target)
+			}
+			return s.in(expr, t, func(i int) (data.Data, error) { return list[i](frame) })
+		}
+		if expr.IsNot {
+			return not(p)
+		}
+		return p
+	case *ast.PredicateBetween:
+		var (
+			target = s.compile(expr.Target)
+			left   = s.compile(expr.Left)
+			right  = s.compile(expr.Right)
+		)
+		p = func(frame Frame) (data.Data, error) {
+			t, err := target(frame)
+			if err != nil {
+				return nil, errors.Wrap(err, "target %s", expr.Target)
+			}
+			l, err := left(frame)
+			if err != nil {
+				return nil, errors.Wrap(err, "left %s", expr.Left)
+			}
+			r, err := right(frame)
+			if err != nil {
+				return nil, errors.Wrap(err, "right %s", expr.Right)
+			}
+			return s.between(expr, t, l, r)
+		}
+		if expr.IsNot {
+			return not(p)
+		}
+		return p
+	case *ast.PredicateLike:
+		var (
+			target  = s.compile(expr.Target)
+			pattern = s.compile(expr.Pattern)
+		)
+		p = func(frame Frame) (data.Data, error) {
+			t, err := target(frame)
+			if err != nil {
+				return nil, errors.Wrap(err, "target %s", expr.Target)
+			}
+			x, err := pattern(frame)
+			if err != nil {
+				return nil, errors.Wrap(err, "pattern %s", expr.Pattern)
+			}
+			return s.like(expr, t, x)
+		}
+		if expr.IsNot {
+			return not(p)
+		}
+		return p
+	case *ast.PredicateBitExpr:
+		return s.compile(expr.Expr)
+	default:
+		return fail(errors.Wrap(ErrUnknownExpr, "%s", logger.JSON(expr)))
+	}
+}
+
+// not negates the bool result of p.
+func not(p Program) Program {
+	return func(frame Frame) (data.Data, error) {
+		r, err := p(frame)
+		if err != nil {
+			return nil, err
+		}
+		return data.FromBool(!r.Bool()), nil
+	}
+}
+
+func (s *compiler) compileBoolPrimary(expr ast.BoolPrimary) Program {
+	switch expr := expr.(type) {
+	case *ast.BoolPrimaryComparison:
+		var (
+			op    = expr.Op
+			left  = s.compile(expr.Left)
+			right = s.compile(expr.Right)
+		)
+		return func(frame Frame) (data.Data, error) {
+			l, err := left(frame)
+			if err != nil {
+				return nil, errors.Wrap(err, "left arg %s", expr.Left)
+			}
+			r, err := right(frame)
+			if err != nil {
+				return nil, errors.Wrap(err, "right arg %s", expr.Right)
+			}
+			result, err := s.compareData(op, l, r)
+			if err != nil {
+				return nil, errors.Wrap(err, "arg %s", expr)
+			}
+			return result, nil
+		}
+	case *ast.BoolPrimaryPredicate:
+		return s.compile(expr.Pred)
+	default:
+		return fail(errors.Wrap(ErrUnknownExpr, "%s", logger.JSON(expr)))
+	}
+}
+
+func (s *compiler) compileLogicalOperator(expr ast.Expr) Program {
+	switch expr := expr.(type) {
+	case ast.BinaryOp:
+		var (
+			left  = s.compile(expr.LeftArg())
+			right = s.compile(expr.RightArg())
+		)
+		return func(frame Frame) (data.Data, error) {
+			l, err := left(frame)
+			if err != nil {
+				return nil, errors.Wrap(err, "left arg %s", expr.LeftArg())
+			}
+			if l.Type() != data.TypeBool {
+				return nil, errors.Wrap(ErrTypeMismatch,
+					"left data: expected bool but got %s from %s", logger.JSON(l), expr.LeftArg())
+			}
+			r, err := right(frame)
+			if err != nil {
+				return nil, errors.Wrap(err, "right arg %s", expr.RightArg())
+			}
+			if r.Type() != data.TypeBool {
+				return nil, errors.Wrap(ErrTypeMismatch,
+					"right data: expected bool but got %s from %s", logger.JSON(r), expr.RightArg())
+			}
+			return s.binaryLogicalOp(expr, l, r)
+		}
+	case ast.UnaryOp:
+		arg := s.compile(expr.Arg())
+		return func(frame Frame) (data.Data, error) {
+			v, err := arg(frame)
+			if err != nil {
+				return nil, errors.Wrap(err, "arg %s", expr.Arg())
+			}
+			return s.unaryLogicalOp(expr, v)
+		}
+	default:
+		return fail(errors.Wrap(ErrUnknownExpr, "%s", logger.JSON(expr)))
+	}
+}
@@ -11,26 +11,11 @@ import (
 )
 
 func NewNormal(env env.Map) Calculator {
-	return NewWithCaller(env, function.NewCallerWithNames(
-		function.NewFactoryBuilder(
-			cast.New(),
-			arithmetic.New(),
-			compare.New(),
-			gogrep.New(),
-		), function.NormalFunctionNames()...,
-	))
+	return NewWithCaller(env, newNormalCaller())
 }
 
 func NewAggregation(env env.Map) Calculator {
-	functionNames := append(function.NormalFunctionNames(), function.AggregationFunctionNames()...)
-	return NewWithCaller(env, function.NewCallerWithNames(
-		function.NewFactoryBuilder(
-			cast.New(),
-			arithmetic.New(),
-			compare.New(),
-			gogrep.New(),
-		), functionNames...,
-	))
+	return NewWithCaller(env, newAggregationCaller())
 }
 
 func NewWithCaller(env env.Map, caller function.Caller) Calculator {
@@ -42,3 +27,58 @@ func NewWithCaller(env env.Map, caller function.Caller) Calculator {
 		env,
 	)
 }
+
+// NewNormalCompiler returns a new Compiler with the functions of NewNormal.
+func NewNormalCompiler(columns Columns, env env.Map) Compiler {
+	return NewCompilerWithCaller(columns, env, newNormalCaller())
+}
+
+// NewAggregationCompiler returns a new Compiler with the functions of NewAggregation.
+func NewAggregationCompiler(columns Columns, env env.Map) Compiler {
+	return NewCompilerWithCaller(columns, env, newAggregationCaller())
+}
+
+// NewNormalCompilerWith returns a factory of Compiler with the functions of NewNormal and functions.
+// functions override the builtin functions of the same names.
+func NewNormalCompilerWith(functions ...function.Function) func(Columns, env.Map) Compiler {
+	return func(columns Columns, env env.Map) Compiler {
+		return NewCompilerWithCaller(columns, env, function.NewOverlayCaller(newNormalCaller(), functions...))
+	}
+}
+
+// NewAggregationCompilerWith returns a factory of Compiler with the functions of NewAggregation and functions.
+// functions override the builtin functions of the same names.
+func NewAggregationCompilerWith(functions ...function.Function) func(Columns, env.Map) Compiler {
+	return func(columns Columns, env env.Map) Compiler {
+		return NewCompilerWithCaller(columns, env, function.NewOverlayCaller(newAggregationCaller(), functions...))
+	}
+}
+
+func NewCompilerWithCaller(columns Columns, env env.Map, caller function.Caller) Compiler {
+	return NewCompiler(
+		compare.New(),
+		arithmetic.New(),
+		bit.New(),
+		caller,
+		columns,
+		env,
+	)
+}
+
+func newFactoryBuilder() function.FactoryBuilder {
+	return function.NewFactoryBuilder(
+		cast.New(),
+		arithmetic.New(),
+		compare.New(),
+		gogrep.New(),
+	)
+}
+
+func newNormalCaller() function.Caller {
+	return function.NewCallerWithNames(newFactoryBuilder(), function.NormalFunctionNames()...)
+}
+
+func newAggregationCaller() function.Caller {
+	functionNames := append(function.NormalFunctionNames(), function.AggregationFunctionNames()...)
+	return function.NewCallerWithNames(newFactoryBuilder(), functionNames...)
+}
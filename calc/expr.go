@@ -228,6 +228,10 @@ func (s *calculator) dataPrefixOp(op ast.PrefixOperatorType, expr ast.SimpleExpr
 	if err != nil {
 		return nil, errors.Wrap(err, "arg %s", logger.JSON(expr))
 	}
+	return s.prefixOp(op, v)
+}
+
+func (s *calculator) prefixOp(op ast.PrefixOperatorType, v data.Data) (data.Data, error) {
 	switch op {
 	case ast.PreOpPlus:
 		return v, nil
@@ -377,6 +381,10 @@ func (s *calculator) dataPredicateLike(expr *ast.PredicateLike) (data.Data, erro
 	if err != nil {
 		return nil, errors.Wrap(err, "pattern %s", logger.JSON(expr.Target))
 	}
+	return s.like(expr, t, p)
+}
+
+func (s *calculator) like(expr *ast.PredicateLike, t, p data.Data) (data.Data, error) {
 	switch s.comparer.Like(t.Value(), p.Value()) {
 	case compare.ResultMatched:
 		return data.FromBool(true), nil
@@ -400,6 +408,10 @@ func (s *calculator) dataPredicateBetween(expr *ast.PredicateBetween) (data.Data
 	if err != nil {
 		return nil, errors.Wrap(err, "right %s", logger.JSON(expr.Right))
 	}
+	return s.between(expr, t, l, r)
+}
+
+func (s *calculator) between(expr *ast.PredicateBetween, t, l, r data.Data) (data.Data, error) {
 	switch s.comparer.Between(t.Value(), l.Value(), r.Value()) {
 	case compare.ResultIn:
 		return data.FromBool(true), nil
@@ -415,11 +427,17 @@ func (s *calculator) dataPredicateIn(expr *ast.PredicateIn) (data.Data, error) {
 	if err != nil {
 		return nil, errors.Wrap(err, "target %s", logger.JSON(expr.Target))
 	}
+	return s.in(expr, t, func(i int) (data.Data, error) { return s.data(expr.List.Exprs[i]) })
+}
+
+// in returns true if t is in the list.
+// elem returns the i-th element of the list.
+func (s *calculator) in(expr *ast.PredicateIn, t data.Data, elem func(i int) (data.Data, error)) (data.Data, error) {
 	switch t.Type() {
 	case data.TypeBool:
 		list := make([]bool, len(expr.List.Exprs))
 		for i, e := range expr.List.Exprs {
-			v, err := s.data(e)
+			v, err := elem(i)
 			if err != nil {
 				return nil, errors.Wrap(err, "list[%d] %s", i, e)
 			}
@@ -440,7 +458,7 @@ func (s *calculator) dataPredicateIn(expr *ast.PredicateIn) (data.Data, error) {
 	case data.TypeInt:
 		list := make([]int, len(expr.List.Exprs))
 		for i, e := range expr.List.Exprs {
-			v, err := s.data(e)
+			v, err := elem(i)
 			if err != nil {
 				return nil, errors.Wrap(err, "list[%d] %s", i, e)
 			}
@@ -461,7 +479,7 @@ func (s *calculator) dataPredicateIn(expr *ast.PredicateIn) (data.Data, error) {
 	case data.TypeFloat:
 		list := make([]float64, len(expr.List.Exprs))
 		for i, e := range expr.List.Exprs {
-			v, err := s.data(e)
+			v, err := elem(i)
 			if err != nil {
 				return nil, errors.Wrap(err, "list[%d] %s", i, e)
 			}
@@ -482,7 +500,7 @@ func (s *calculator) dataPredicateIn(expr *ast.PredicateIn) (data.Data, error) {
 	case data.TypeString:
 		list := make([]string, len(expr.List.Exprs))
 		for i, e := range expr.List.Exprs {
-			v, err := s.data(e)
+			v, err := elem(i)
 			if err != nil {
 				return nil, errors.Wrap(err, "list[%d] %s", i, e)
 			}
@@ -578,33 +596,41 @@ func (s *calculator) dataLogicalOperator(expr ast.Expr) (data.Data, error) {
 			return nil, errors.Wrap(ErrTypeMismatch,
 				"right data: expected bool but got %s from %s", logger.JSON(right), logger.JSON(expr.RightArg()))
 		}
-		switch expr.(type) {
-		case *ast.OrExpr:
-			return data.FromBool(left.Bool() || right.Bool()), nil
-		case *ast.AndExpr:
-			return data.FromBool(left.Bool() && right.Bool()), nil
-		case *ast.XorExpr:
-			return data.FromBool(left.Bool() != right.Bool()), nil
-		default:
-			return nil, errors.Wrap(ErrUnknownExpr,
-				"%s left %s right %s", logger.JSON(expr), logger.JSON(left), logger.JSON(right))
-		}
+		return s.binaryLogicalOp(expr, left, right)
 	case ast.UnaryOp:
 		var arg data.Data
 		if arg, err = s.Data(expr.Arg()); err != nil {
 			return nil, errors.Wrap(err, "arg %s", logger.JSON(expr.Arg()))
 		}
-		if arg.Type() != data.TypeBool {
-			return nil, errors.Wrap(ErrTypeMismatch,
-				"arg: expected bool but got %s from %s", logger.JSON(arg), logger.JSON(expr.Arg()))
-		}
-		switch expr.(type) {
-		case *ast.NotExpr:
-			return data.FromBool(!arg.Bool()), nil
-		default:
-			return nil, errors.Wrap(ErrUnknownExpr, "%s arg %s", logger.JSON(expr), logger.JSON(arg))
-		}
+		return s.unaryLogicalOp(expr, arg)
 	default:
 		return nil, errors.Wrap(ErrUnknownExpr, "%s", logger.JSON(expr))
 	}
 }
+
+func (s *calculator) binaryLogicalOp(expr ast.BinaryOp, left, right data.Data) (data.Data, error) {
+	switch expr.(type) {
+	case *ast.OrExpr:
+		return data.FromBool(left.Bool() || right.Bool()), nil
+	case *ast.AndExpr:
+		return data.FromBool(left.Bool() && right.Bool()), nil
+	case *ast.XorExpr:
+		return data.FromBool(left.Bool() != right.Bool()), nil
+	default:
+		return nil, errors.Wrap(ErrUnknownExpr,
+			"%s left %s right %s", logger.JSON(expr), logger.JSON(left), logger.JSON(right))
+	}
+}
+
+func (s *calculator) unaryLogicalOp(expr ast.UnaryOp, arg data.Data) (data.Data, error) {
+	if arg.Type() != data.TypeBool {
+		return nil, errors.Wrap(ErrTypeMismatch,
+			"arg: expected bool but got %s from %s", logger.JSON(arg), logger.JSON(expr.Arg()))
+	}
+	switch expr.(type) {
+	case *ast.NotExpr:
+		return data.FromBool(!arg.Bool()), nil
+	default:
+		return nil, errors.Wrap(ErrUnknownExpr, "%s arg %s", logger.JSON(expr), logger.JSON(arg))
+	}
+}
@@ -36,6 +36,7 @@ type yySymType struct {
 	groupBySection *ast.GroupBySection
 	whereCondition *ast.WhereCondition
 	whereSection   *ast.WhereSection
+	pruneSection   *ast.PruneSection
 	selectOption   *ast.SelectOption
 	ident          *ast.Ident
 	selectTarget   *ast.SelectTarget
@@ -53,39 +54,40 @@ const GROUP = 57350
 const BY = 57351
 const ORDER = 57352
 const LIMIT = 57353
-const IDENT = 57354
-const INT = 57355
-const FLOAT = 57356
-const STRING = 57357
-const AS = 57358
-const ASC = 57359
-const DESC = 57360
-const LIKE = 57361
-const IN = 57362
-const COMMA = 57363
-const SCOLON = 57364
-const LPAR = 57365
-const RPAR = 57366
-const PLUS = 57367
-const MINUS = 57368
-const AST = 57369
-const SLASH = 57370
-const NOT = 57371
-const AND = 57372
-const OR = 57373
-const XOR = 57374
-const EQ = 57375
-const NE = 57376
-const GT = 57377
-const GQ = 57378
-const LT = 57379
-const LQ = 57380
-const BETWEEN = 57381
-const OFFSET = 57382
-const AMP = 57383
-const PIPE = 57384
-const HAT = 57385
-const TILDE = 57386
+const PRUNE = 57354
+const IDENT = 57355
+const INT = 57356
+const FLOAT = 57357
+const STRING = 57358
+const AS = 57359
+const ASC = 57360
+const DESC = 57361
+const LIKE = 57362
+const IN = 57363
+const COMMA = 57364
+const SCOLON = 57365
+const LPAR = 57366
+const RPAR = 57367
+const PLUS = 57368
+const MINUS = 57369
+const AST = 57370
+const SLASH = 57371
+const NOT = 57372
+const AND = 57373
+const OR = 57374
+const XOR = 57375
+const EQ = 57376
+const NE = 57377
+const GT = 57378
+const GQ = 57379
+const LT = 57380
+const LQ = 57381
+const BETWEEN = 57382
+const OFFSET = 57383
+const AMP = 57384
+const PIPE = 57385
+const HAT = 57386
+const TILDE = 57387
 
 var yyToknames = [...]string{
 	"$end",
@@ -99,6 +101,7 @@ var yyToknames = [...]string{
 	"BY",
 	"ORDER",
 	"LIMIT",
+	"PRUNE",
 	"IDENT",
 	"INT",
 	"FLOAT",
@@ -141,115 +144,118 @@ const yyEofCode = 1
 const yyErrCode = 2
 const yyInitialStackSize = 16
 
-//line cc/dql.y:468
+//line cc/dql.y:481
 
 //line yacctab:1
 var yyExca = [...]int{
 	-1, 1,
 	1, -1,
 	-2, 0,
-	-1, 17,
-	19, 41,
-	20, 41,
-	39, 41,
-	-2, 54,
+	-1, 20,
+	20, 43,
+	21, 43,
+	40, 43,
+	-2, 56,
 }
 
 const yyPrivate = 57344
 
-const yyLast = 157
+const yyLast = 161
 
 var yyAct = [...]int{
-	13, 98, 16, 82, 18, 31, 70, 17, 105, 33,
-	12, 78, 76, 39, 84, 40, 19, 27, 28, 29,
-	38, 37, 39, 38, 62, 39, 60, 23, 91, 24,
-	25, 77, 94, 14, 106, 59, 5, 71, 72, 73,
-	74, 38, 37, 39, 75, 95, 68, 95, 26, 90,
-	110, 63, 19, 27, 28, 29, 86, 79, 80, 67,
-	83, 112, 65, 23, 33, 24, 25, 96, 88, 85,
-	55, 56, 57, 58, 89, 102, 42, 43, 44, 45,
-	46, 47, 36, 93, 26, 92, 52, 53, 54, 11,
-	99, 71, 83, 9, 35, 101, 103, 100, 19, 27,
-	28, 29, 4, 7, 21, 111, 50, 99, 113, 23,
-	49, 24, 25, 41, 81, 61, 55, 56, 57, 58,
-	51, 55, 56, 57, 58, 20, 22, 108, 109, 15,
-	26, 107, 52, 53, 54, 48, 104, 52, 53, 54,
-	38, 37, 39, 87, 97, 66, 34, 69, 10, 6,
-	8, 64, 32, 30, 3, 1, 2,
+	36, 105, 19, 89, 21, 82, 20, 35, 111, 16,
+	46, 47, 48, 49, 50, 51, 78, 76, 44, 14,
+	37, 22, 30, 31, 32, 43, 42, 66, 43, 64,
+	94, 91, 26, 63, 27, 28, 77, 95, 17, 16,
+	108, 5, 72, 73, 74, 42, 41, 43, 75, 112,
+	99, 95, 38, 29, 22, 30, 31, 32, 70, 40,
+	79, 80, 116, 103, 83, 26, 71, 27, 28, 7,
+	90, 65, 97, 86, 98, 69, 87, 22, 30, 31,
+	32, 34, 68, 93, 92, 9, 29, 84, 26, 12,
+	27, 28, 83, 42, 41, 43, 102, 100, 11, 106,
+	90, 4, 24, 107, 109, 59, 60, 61, 62, 29,
+	101, 54, 53, 106, 117, 59, 60, 61, 62, 55,
+	45, 56, 57, 58, 59, 60, 61, 62, 81, 23,
+	25, 56, 57, 58, 114, 115, 18, 113, 52, 110,
+	56, 57, 58, 96, 104, 85, 67, 42, 41, 43,
+	88, 33, 6, 10, 8, 39, 15, 13, 3, 1,
+	2,
 }
 
 var yyPact = [...]int{
-	98, -1000, 14, 97, 88, -1000, 81, 4, 4, -1000,
-	87, 73, -1000, 11, 40, 43, -1000, 91, -1000, 12,
-	-1000, 86, -1000, 4, -1000, -1000, -1000, -1000, -1000, -1000,
-	30, -1000, 46, 11, 49, 4, 4, 4, 4, 4,
-	-1000, 86, -1000, -1000, -1000, -1000, -1000, -1000, -8, 86,
-	86, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, 4,
-	-1000, -1000, -10, 4, -1000, 44, 57, 65, -1000, 28,
-	-1000, 11, -7, -19, -1000, -1000, 5, 86, 86, 96,
-	96, 8, 24, 11, -1000, -1000, -1000, -1000, 54, 4,
-	4, 4, 45, -1000, -1000, 4, -32, 13, -1000, 110,
-	-1000, 26, 86, 11, -1000, 48, 4, -1000, -1000, -1000,
-	-1000, -1000, -1000, -1000,
+	97, -1000, 18, 57, 80, -1000, 92, 83, 8, -1000,
+	73, 8, 8, 30, -1000, 42, 14, 64, -24, -1000,
+	89, -1000, 9, -1000, 41, -1000, 8, -1000, -1000, -1000,
+	-1000, -1000, -1000, 75, 66, -1000, 14, -1000, 8, -1000,
+	53, 8, 8, 8, -1000, 41, -1000, -1000, -1000, -1000,
+	-1000, -1000, -4, 41, 41, -1000, -1000, -1000, -1000, -1000,
+	-1000, -1000, -1000, 8, -1000, -1000, 62, 63, 8, 8,
+	-1000, -1000, -5, -8, -1000, -1000, 7, 41, 41, 98,
+	98, 5, 29, 14, -1000, 61, 65, -1000, 28, -1000,
+	14, 8, 79, -1000, -1000, 8, -1000, 49, 8, 8,
+	15, 41, 14, -33, 27, -1000, 116, -1000, -1000, -1000,
+	-1000, 48, 8, -1000, -1000, -1000, -1000, -1000,
 }
 
 var yyPgo = [...]int{
-	0, 156, 155, 154, 153, 5, 152, 151, 150, 149,
-	10, 148, 147, 6, 146, 145, 144, 1, 143, 136,
-	0, 135, 131, 129, 2, 7, 126, 125, 4, 114,
-	3, 113, 110, 106, 104,
+	0, 160, 159, 158, 157, 19, 156, 155, 154, 153,
+	152, 7, 151, 150, 3, 146, 145, 144, 1, 143,
+	139, 0, 138, 137, 136, 2, 6, 130, 129, 4,
+	128, 5, 120, 112, 111, 102,
 }
 
 var yyR1 = [...]int{
 	0, 2, 1, 3, 4, 4, 5, 6, 7, 7,
-	8, 8, 9, 9, 10, 11, 11, 12, 12, 13,
-	14, 14, 15, 15, 16, 16, 17, 22, 22, 22,
-	18, 18, 19, 19, 30, 30, 20, 20, 20, 20,
-	20, 21, 21, 23, 23, 31, 31, 31, 31, 31,
-	31, 24, 24, 24, 24, 25, 25, 25, 33, 33,
-	33, 33, 32, 32, 32, 28, 28, 28, 28, 28,
-	34, 34, 34, 34, 26, 26, 26, 27, 29, 29,
+	8, 8, 10, 10, 9, 9, 11, 12, 12, 13,
+	13, 14, 15, 15, 16, 16, 17, 17, 18, 23,
+	23, 23, 19, 19, 20, 20, 31, 31, 21, 21,
+	21, 21, 21, 22, 22, 24, 24, 32, 32, 32,
+	32, 32, 32, 25, 25, 25, 25, 26, 26, 26,
+	34, 34, 34, 34, 33, 33, 33, 29, 29, 29,
+	29, 29, 35, 35, 35, 35, 27, 27, 27, 28,
+	30, 30,
 }
 
 var yyR2 = [...]int{
-	0, 2, 6, 3, 1, 3, 2, 1, 0, 2,
-	0, 1, 0, 2, 1, 0, 3, 1, 3, 1,
-	0, 2, 0, 3, 1, 3, 2, 0, 1, 1,
-	0, 3, 0, 2, 1, 3, 3, 3, 3, 2,
-	1, 0, 1, 3, 1, 1, 1, 1, 1, 1,
-	1, 6, 6, 4, 1, 3, 3, 1, 1, 1,
-	1, 1, 1, 1, 1, 1, 1, 2, 1, 3,
-	1, 1, 1, 1, 1, 1, 1, 4, 0, 1,
+	0, 2, 7, 3, 1, 3, 2, 1, 0, 2,
+	0, 1, 0, 3, 0, 2, 1, 0, 3, 1,
+	3, 1, 0, 2, 0, 3, 1, 3, 2, 0,
+	1, 1, 0, 3, 0, 2, 1, 3, 3, 3,
+	3, 2, 1, 0, 1, 3, 1, 1, 1, 1,
+	1, 1, 1, 6, 6, 4, 1, 3, 3, 1,
+	1, 1, 1, 1, 1, 1, 1, 1, 1, 2,
+	1, 3, 1, 1, 1, 1, 1, 1, 1, 4,
+	0, 1,
 }
 
 var yyChk = [...]int{
-	-1000, -2, -1, -3, 4, 22, -9, 6, -8, 5,
-	-11, 8, -10, -20, 29, -23, -24, -25, -28, 12,
-	-27, -34, -26, 23, 25, 26, 44, 13, 14, 15,
-	-4, -5, -6, -20, -14, 7, 9, 31, 30, 32,
-	-20, -31, 33, 34, 35, 36, 37, 38, -21, -32,
-	-33, 29, 41, 42, 43, 25, 26, 27, 28, 23,
-	-28, 29, -20, 21, -7, 16, -15, 10, -10, -12,
-	-13, -20, -20, -20, -20, -24, 20, 39, 19, -25,
-	-25, -29, -30, -20, 24, -5, 12, -18, 11, 9,
-	21, 23, -25, -28, 24, 21, 13, -16, -17, -20,
-	-13, -30, 30, -20, -19, 40, 21, -22, 17, 18,
-	24, -24, 13, -17,
+	-1000, -2, -1, -3, 4, 23, -10, 12, -8, 5,
+	-9, 6, 6, -4, -5, -6, -21, 30, -24, -25,
+	-26, -29, 13, -28, -35, -27, 24, 26, 27, 45,
+	14, 15, 16, -12, 8, -11, -21, -11, 22, -7,
+	17, 32, 31, 33, -21, -32, 34, 35, 36, 37,
+	38, 39, -22, -33, -34, 30, 42, 43, 44, 26,
+	27, 28, 29, 24, -29, 30, -21, -15, 7, 9,
+	-5, 13, -21, -21, -21, -25, 21, 40, 20, -26,
+	-26, -30, -31, -21, 25, -16, 10, -11, -13, -14,
+	-21, 24, -26, -29, 25, 22, -19, 11, 9, 22,
+	-31, 31, -21, 14, -17, -18, -21, -14, 25, -25,
+	-20, 41, 22, -23, 18, 19, 14, -18,
 }
 
 var yyDef = [...]int{
-	0, -2, 0, 12, 10, 1, 15, 0, 0, 11,
-	20, 0, 13, 14, 73, 40, 44, -2, 57, 65,
-	66, 0, 68, 0, 70, 71, 72, 74, 75, 76,
-	3, 4, 8, 7, 22, 0, 0, 0, 0, 0,
-	39, 0, 45, 46, 47, 48, 49, 50, 0, 0,
-	0, 42, 62, 63, 64, 58, 59, 60, 61, 78,
-	67, 73, 0, 0, 6, 0, 30, 0, 21, 16,
-	17, 19, 36, 37, 38, 43, 0, 0, 0, 55,
-	56, 0, 79, 34, 69, 5, 9, 2, 0, 0,
-	0, 0, 0, 53, 77, 0, 32, 23, 24, 27,
-	18, 0, 0, 35, 31, 0, 0, 26, 28, 29,
-	51, 52, 33, 25,
+	0, -2, 0, 12, 10, 1, 14, 0, 0, 11,
+	17, 0, 0, 3, 4, 8, 7, 75, 42, 46,
+	-2, 59, 67, 68, 0, 70, 0, 72, 73, 74,
+	76, 77, 78, 22, 0, 15, 16, 13, 0, 6,
+	0, 0, 0, 0, 41, 0, 47, 48, 49, 50,
+	51, 52, 0, 0, 0, 44, 64, 65, 66, 60,
+	61, 62, 63, 80, 69, 75, 0, 24, 0, 0,
+	5, 9, 38, 39, 40, 45, 0, 0, 0, 57,
+	58, 0, 81, 36, 71, 32, 0, 23, 18, 19,
+	21, 0, 0, 55, 79, 0, 2, 0, 0, 0,
+	0, 0, 37, 34, 25, 26, 29, 20, 53, 54,
+	33, 0, 0, 28, 30, 31, 35, 27,
 }
 
 var yyTok1 = [...]int{
@@ -261,7 +267,7 @@ var yyTok2 = [...]int{
 	12, 13, 14, 15, 16, 17, 18, 19, 20, 21,
 	22, 23, 24, 25, 26, 27, 28, 29, 30, 31,
 	32, 33, 34, 35, 36, 37, 38, 39, 40, 41,
-	42, 43, 44, 45,
+	42, 43, 44, 45, 46,
 }
 
 var yyTok3 = [...]int{
@@ -607,27 +613,28 @@ yydefault:
 
 	case 1:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line cc/dql.y:139
+//line cc/dql.y:142
 		{
 			yylex.(Lexer).SetResult(yyDollar[1].statement)
 			yyVAL.statement = yyDollar[1].statement
 		}
 	case 2:
-		yyDollar = yyS[yypt-6 : yypt+1]
-//line cc/dql.y:150
+		yyDollar = yyS[yypt-7 : yypt+1]
+//line cc/dql.y:154
 		{
 			yyVAL.statement = &ast.Statement{
 				SelectSection:  yyDollar[1].selectSection,
-				WhereSection:   yyDollar[2].whereSection,
-				GroupBySection: yyDollar[3].groupBySection,
-				HavingSection:  yyDollar[4].havingSection,
-				OrderBySection: yyDollar[5].orderBySection,
-				LimitSection:   yyDollar[6].limitSection,
+				PruneSection:   yyDollar[2].pruneSection,
+				WhereSection:   yyDollar[3].whereSection,
+				GroupBySection: yyDollar[4].groupBySection,
+				HavingSection:  yyDollar[5].havingSection,
+				OrderBySection: yyDollar[6].orderBySection,
+				LimitSection:   yyDollar[7].limitSection,
 			}
 		}
 	case 3:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line cc/dql.y:164
+//line cc/dql.y:169
 		{
 			yyVAL.selectSection = &ast.SelectSection{
 				Option: yyDollar[2].selectOption,
@@ -636,20 +643,20 @@ yydefault:
 		}
 	case 4:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line cc/dql.y:172
+//line cc/dql.y:177
 		{
 			yyVAL.selectTerms = &ast.SelectTerms{Terms: []*ast.SelectTerm{yyDollar[1].selectTerm}}
 		}
 	case 5:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line cc/dql.y:175
+//line cc/dql.y:180
 		{
 			v := append(yyDollar[1].selectTerms.Terms, yyDollar[3].selectTerm)
 			yyVAL.selectTerms = &ast.SelectTerms{Terms: v}
 		}
 	case 6:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line cc/dql.y:181
+//line cc/dql.y:186
 		{
 			yyVAL.selectTerm = &ast.SelectTerm{
 				Target: yyDollar[1].selectTarget,
@@ -658,123 +665,135 @@ yydefault:
 		}
 	case 7:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line cc/dql.y:189
+//line cc/dql.y:194
 		{
 			yyVAL.selectTarget = &ast.SelectTarget{Expr: yyDollar[1].expr}
 		}
 	case 8:
 		yyDollar = yyS[yypt-0 : yypt+1]
-//line cc/dql.y:194
+//line cc/dql.y:199
 		{
 			yyVAL.ident = nil
 		}
 	case 9:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line cc/dql.y:197
+//line cc/dql.y:202
 		{
 			yyVAL.ident = &ast.Ident{Value: yyDollar[2].token.Value()}
 		}
 	case 10:
 		yyDollar = yyS[yypt-0 : yypt+1]
-//line cc/dql.y:202
+//line cc/dql.y:207
 		{
 			yyVAL.selectOption = nil
 		}
 	case 11:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line cc/dql.y:205
+//line cc/dql.y:210
 		{
 			yyVAL.selectOption = &ast.SelectOption{IsDistinct: true}
 		}
 	case 12:
 		yyDollar = yyS[yypt-0 : yypt+1]
-//line cc/dql.y:210
+//line cc/dql.y:215
 		{
-			yyVAL.whereSection = nil
+			yyVAL.pruneSection = nil
 		}
 	case 13:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line cc/dql.y:218
+		{
+			yyVAL.pruneSection = &ast.PruneSection{Condition: yyDollar[3].whereCondition}
+		}
+	case 14:
+		yyDollar = yyS[yypt-0 : yypt+1]
+//line cc/dql.y:223
+		{
+			yyVAL.whereSection = nil
+		}
+	case 15:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line cc/dql.y:213
+//line cc/dql.y:226
 		{
 			yyVAL.whereSection = &ast.WhereSection{Condition: yyDollar[2].whereCondition}
 		}
-	case 14:
+	case 16:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line cc/dql.y:218
+//line cc/dql.y:231
 		{
 			yyVAL.whereCondition = &ast.WhereCondition{Expr: yyDollar[1].expr}
 		}
-	case 15:
+	case 17:
 		yyDollar = yyS[yypt-0 : yypt+1]
-//line cc/dql.y:223
+//line cc/dql.y:236
 		{
 			yyVAL.groupBySection = nil
 		}
-	case 16:
+	case 18:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line cc/dql.y:226
+//line cc/dql.y:239
 		{
 			yyVAL.groupBySection = &ast.GroupBySection{Terms: yyDollar[3].groupByTerms}
 		}
-	case 17:
+	case 19:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line cc/dql.y:231
+//line cc/dql.y:244
 		{
 			yyVAL.groupByTerms = &ast.GroupByTerms{Terms: []*ast.GroupByTerm{yyDollar[1].groupByTerm}}
 		}
-	case 18:
+	case 20:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line cc/dql.y:234
+//line cc/dql.y:247
 		{
 			v := append(yyDollar[1].groupByTerms.Terms, yyDollar[3].groupByTerm)
 			yyVAL.groupByTerms = &ast.GroupByTerms{Terms: v}
 		}
-	case 19:
+	case 21:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line cc/dql.y:240
+//line cc/dql.y:253
 		{
 			yyVAL.groupByTerm = &ast.GroupByTerm{Expr: yyDollar[1].expr}
 		}
-	case 20:
+	case 22:
 		yyDollar = yyS[yypt-0 : yypt+1]
-//line cc/dql.y:245
+//line cc/dql.y:258
 		{
 			yyVAL.havingSection = nil
 		}
-	case 21:
+	case 23:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line cc/dql.y:248
+//line cc/dql.y:261
 		{
 			yyVAL.havingSection = &ast.HavingSection{Condition: yyDollar[2].whereCondition}
 		}
-	case 22:
+	case 24:
 		yyDollar = yyS[yypt-0 : yypt+1]
-//line cc/dql.y:253
+//line cc/dql.y:266
 		{
 			yyVAL.orderBySection = nil
 		}
-	case 23:
+	case 25:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line cc/dql.y:256
+//line cc/dql.y:269
 		{
 			yyVAL.orderBySection = &ast.OrderBySection{Terms: yyDollar[3].orderByTerms}
 		}
-	case 24:
+	case 26:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line cc/dql.y:261
+//line cc/dql.y:274
 		{
 			yyVAL.orderByTerms = &ast.OrderByTerms{Terms: []*ast.OrderByTerm{yyDollar[1].orderByTerm}}
 		}
-	case 25:
+	case 27:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line cc/dql.y:264
+//line cc/dql.y:277
 		{
 			v := append(yyDollar[1].orderByTerms.Terms, yyDollar[3].orderByTerm)
 			yyVAL.orderByTerms = &ast.OrderByTerms{Terms: v}
 		}
-	case 26:
+	case 28:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line cc/dql.y:270
+//line cc/dql.y:283
 		{
 			opt := &ast.OrderByTermOption{
 				IsDesc: yyDollar[2].flag,
@@ -784,113 +803,113 @@ yydefault:
 				Option: opt,
 			}
 		}
-	case 27:
+	case 29:
 		yyDollar = yyS[yypt-0 : yypt+1]
-//line cc/dql.y:281
+//line cc/dql.y:294
 		{
 			yyVAL.flag = false
 		}
-	case 28:
+	case 30:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line cc/dql.y:284
+//line cc/dql.y:297
 		{
 			yyVAL.flag = false
 		}
-	case 29:
+	case 31:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line cc/dql.y:287
+//line cc/dql.y:300
 		{
 			yyVAL.flag = true
 		}
-	case 30:
+	case 32:
 		yyDollar = yyS[yypt-0 : yypt+1]
-//line cc/dql.y:292
+//line cc/dql.y:305
 		{
 			yyVAL.limitSection = nil
 		}
-	case 31:
+	case 33:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line cc/dql.y:295
+//line cc/dql.y:308
 		{
 			l := yylex.(Lexer)
 			v := l.ParseInt(yyDollar[2].token.Value())
 			yyVAL.limitSection = &ast.LimitSection{
-				Limit:  &ast.IntLit{Value: v},
+				Limit:  &ast.IntLit{Value: v, Raw: yyDollar[2].token.Value()},
 				Offset: yyDollar[3].intLit,
 			}
 		}
-	case 32:
+	case 34:
 		yyDollar = yyS[yypt-0 : yypt+1]
-//line cc/dql.y:305
+//line cc/dql.y:318
 		{
 			yyVAL.intLit = nil
 		}
-	case 33:
+	case 35:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line cc/dql.y:308
+//line cc/dql.y:321
 		{
 			l := yylex.(Lexer)
 			v := l.ParseInt(yyDollar[2].token.Value())
-			yyVAL.intLit = &ast.IntLit{Value: v}
+			yyVAL.intLit = &ast.IntLit{Value: v, Raw: yyDollar[2].token.Value()}
 		}
-	case 34:
+	case 36:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line cc/dql.y:315
+//line cc/dql.y:328
 		{
 			yyVAL.exprs = &ast.Exprs{Exprs: []ast.Expr{yyDollar[1].expr}}
 		}
-	case 35:
+	case 37:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line cc/dql.y:318
+//line cc/dql.y:331
 		{
 			v := append(yyDollar[1].exprs.Exprs, yyDollar[3].expr)
 			yyVAL.exprs = &ast.Exprs{Exprs: v}
 		}
-	case 36:
+	case 38:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line cc/dql.y:324
+//line cc/dql.y:337
 		{
 			yyVAL.expr = &ast.OrExpr{Left: yyDollar[1].expr, Right: yyDollar[3].expr}
 		}
-	case 37:
+	case 39:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line cc/dql.y:327
+//line cc/dql.y:340
 		{
 			yyVAL.expr = &ast.AndExpr{Left: yyDollar[1].expr, Right: yyDollar[3].expr}
 		}
-	case 38:
+	case 40:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line cc/dql.y:330
+//line cc/dql.y:343
 		{
 			yyVAL.expr = &ast.XorExpr{Left: yyDollar[1].expr, Right: yyDollar[3].expr}
 		}
-	case 39:
+	case 41:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line cc/dql.y:333
+//line cc/dql.y:346
 		{
 			yyVAL.expr = &ast.NotExpr{Expr: yyDollar[2].expr}
 		}
-	case 40:
+	case 42:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line cc/dql.y:336
+//line cc/dql.y:349
 		{
 			yyVAL.expr = yyDollar[1].boolPrimary
 		}
-	case 41:
+	case 43:
 		yyDollar = yyS[yypt-0 : yypt+1]
-//line cc/dql.y:341
+//line cc/dql.y:354
 		{
 			yyVAL.flag = false
 		}
-	case 42:
+	case 44:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line cc/dql.y:344
+//line cc/dql.y:357
 		{
 			yyVAL.flag = true
 		}
-	case 43:
+	case 45:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line cc/dql.y:349
+//line cc/dql.y:362
 		{
 			l := yylex.(Lexer)
 			op := l.AsComparisonType(yyDollar[2].token.Type())
@@ -900,15 +919,15 @@ yydefault:
 				Right: yyDollar[3].predicate,
 			}
 		}
-	case 44:
+	case 46:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line cc/dql.y:358
+//line cc/dql.y:371
 		{
 			yyVAL.boolPrimary = &ast.BoolPrimaryPredicate{Pred: yyDollar[1].predicate}
 		}
-	case 51:
+	case 53:
 		yyDollar = yyS[yypt-6 : yypt+1]
-//line cc/dql.y:366
+//line cc/dql.y:379
 		{
 			yyVAL.predicate = &ast.PredicateIn{
 				IsNot:  yyDollar[2].flag,
@@ -916,9 +935,9 @@ yydefault:
 				List:   yyDollar[5].exprs,
 			}
 		}
-	case 52:
+	case 54:
 		yyDollar = yyS[yypt-6 : yypt+1]
-//line cc/dql.y:373
+//line cc/dql.y:386
 		{
 			yyVAL.predicate = &ast.PredicateBetween{
 				IsNot:  yyDollar[2].flag,
@@ -927,9 +946,9 @@ yydefault:
 				Right:  yyDollar[6].predicate,
 			}
 		}
-	case 53:
+	case 55:
 		yyDollar = yyS[yypt-4 : yypt+1]
-//line cc/dql.y:381
+//line cc/dql.y:394
 		{
 			yyVAL.predicate = &ast.PredicateLike{
 				IsNot:   yyDollar[2].flag,
@@ -937,92 +956,92 @@ yydefault:
 				Pattern: yyDollar[4].simpleExpr,
 			}
 		}
-	case 54:
+	case 56:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line cc/dql.y:388
+//line cc/dql.y:401
 		{
 			yyVAL.predicate = &ast.PredicateBitExpr{Expr: yyDollar[1].bitExpr}
 		}
-	case 55:
+	case 57:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line cc/dql.y:393
+//line cc/dql.y:406
 		{
 			l := yylex.(Lexer)
 			op := l.AsBitOperatorType(yyDollar[2].token.Type())
 			yyVAL.bitExpr = &ast.BitExprBitOp{Op: op, Left: yyDollar[1].bitExpr, Right: yyDollar[3].bitExpr}
 		}
-	case 56:
+	case 58:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line cc/dql.y:398
+//line cc/dql.y:411
 		{
 			l := yylex.(Lexer)
 			op := l.AsArithmeticOperatorType(yyDollar[2].token.Type())
 			yyVAL.bitExpr = &ast.BitExprArtOp{Op: op, Left: yyDollar[1].bitExpr, Right: yyDollar[3].bitExpr}
 		}
-	case 57:
+	case 59:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line cc/dql.y:403
+//line cc/dql.y:416
 		{
 			yyVAL.bitExpr = &ast.BitExprSimpleExpr{Expr: yyDollar[1].simpleExpr}
 		}
-	case 65:
+	case 67:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line cc/dql.y:414
+//line cc/dql.y:427
 		{
 			yyVAL.simpleExpr = &ast.Ident{Value: yyDollar[1].token.Value()}
 		}
-	case 66:
+	case 68:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line cc/dql.y:417
+//line cc/dql.y:430
 		{
 			yyVAL.simpleExpr = yyDollar[1].simpleExpr
 		}
-	case 67:
+	case 69:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line cc/dql.y:420
+//line cc/dql.y:433
 		{
 			l := yylex.(Lexer)
 			op := l.AsPrefixOperatorType(yyDollar[1].token.Type())
 			yyVAL.simpleExpr = &ast.SimpleExprPrefixOp{Op: op, Expr: yyDollar[2].simpleExpr}
 		}
-	case 68:
+	case 70:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line cc/dql.y:425
+//line cc/dql.y:438
 		{
 			yyVAL.simpleExpr = &ast.SimpleExprLit{Lit: yyDollar[1].lit}
 		}
-	case 69:
+	case 71:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line cc/dql.y:428
+//line cc/dql.y:441
 		{
 			yyVAL.simpleExpr = &ast.SimpleExprExpr{Expr: yyDollar[2].expr}
 		}
-	case 74:
+	case 76:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line cc/dql.y:436
+//line cc/dql.y:449
 		{
 			l := yylex.(Lexer)
 			v := l.ParseInt(yyDollar[1].token.Value())
-			yyVAL.lit = &ast.IntLit{Value: v}
+			yyVAL.lit = &ast.IntLit{Value: v, Raw: yyDollar[1].token.Value()}
 		}
-	case 75:
+	case 77:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line cc/dql.y:441
+//line cc/dql.y:454
 		{
 			l := yylex.(Lexer)
 			v := l.ParseFloat(yyDollar[1].token.Value())
 			yyVAL.lit = &ast.FloatLit{Value: v}
 		}
-	case 76:
+	case 78:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line cc/dql.y:446
+//line cc/dql.y:459
 		{
 			v := yyDollar[1].token.Value()
 			yyVAL.lit = &ast.StringLit{Value: v}
 		}
-	case 77:
+	case 79:
 		yyDollar = yyS[yypt-4 : yypt+1]
-//line cc/dql.y:452
+//line cc/dql.y:465
 		{
 			name := &ast.Ident{Value: yyDollar[1].token.Value()}
 			yyVAL.simpleExpr = &ast.FunctionCall{
@@ -1030,15 +1049,15 @@ yydefault:
 				Arguments:    yyDollar[3].exprs,
 			}
 		}
-	case 78:
+	case 80:
 		yyDollar = yyS[yypt-0 : yypt+1]
-//line cc/dql.y:461
+//line cc/dql.y:474
 		{
 			yyVAL.exprs = &ast.Exprs{}
 		}
-	case 79:
+	case 81:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line cc/dql.y:464
+//line cc/dql.y:477
 		{
 			yyVAL.exprs = yyDollar[1].exprs
 		}
@@ -29,6 +29,7 @@ import (
   groupBySection *ast.GroupBySection
   whereCondition *ast.WhereCondition
   whereSection *ast.WhereSection
+  pruneSection *ast.PruneSection
   selectOption *ast.SelectOption
   ident *ast.Ident
   selectTarget *ast.SelectTarget
@@ -46,6 +47,7 @@ import (
 %type <ident> select_as_term
 %type <selectOption> select_option
 %type <whereSection> where_section
+%type <pruneSection> prune_section
 %type <whereCondition> where_condition
 %type <groupBySection> group_by_section
 %type <groupByTerms> group_by_terms
@@ -78,6 +80,7 @@ import (
 %token <token> BY  /* by */
 %token <token> ORDER  /* order */
 %token <token> LIMIT  /* limit */
+%token <token> PRUNE  /* prune */
 
 %token <token> IDENT  /* identifier */
 %token <token> INT  /* integer */
@@ -143,6 +146,7 @@ top:
 
 statement:
   select_section
+  prune_section
   where_section
   group_by_section
   having_section
@@ -150,11 +154,12 @@ statement:
   limit_section {
     $$ = &ast.Statement{
       SelectSection: $1,
-      WhereSection: $2,
-      GroupBySection: $3,
-      HavingSection: $4,
-      OrderBySection: $5,
-      LimitSection: $6,
+      PruneSection: $2,
+      WhereSection: $3,
+      GroupBySection: $4,
+      HavingSection: $5,
+      OrderBySection: $6,
+      LimitSection: $7,
     }
   }
 
@@ -206,6 +211,14 @@ select_option:
     $$ = &ast.SelectOption{IsDistinct: true}
   }
 
+prune_section:
+  {
+    $$ = nil
+  }
+  | PRUNE WHERE where_condition {
+    $$ = &ast.PruneSection{Condition: $3}
+  }
+
 where_section:
   {
     $$ = nil
@@ -296,7 +309,7 @@ limit_section:
     l := yylex.(Lexer)
     v := l.ParseInt($2.Value())
     $$ = &ast.LimitSection{
-      Limit: &ast.IntLit{Value: v},
+      Limit: &ast.IntLit{Value: v, Raw: $2.Value()},
       Offset: $3,
     }
   }
@@ -308,7 +321,7 @@ limit_offset:
   | OFFSET INT {
     l := yylex.(Lexer)
     v := l.ParseInt($2.Value())
-    $$ = &ast.IntLit{Value: v}
+    $$ = &ast.IntLit{Value: v, Raw: $2.Value()}
   }
 
 exprs:
@@ -436,7 +449,7 @@ literal:
   INT {
     l := yylex.(Lexer)
     v := l.ParseInt($1.Value())
-    $$ = &ast.IntLit{Value: v}
+    $$ = &ast.IntLit{Value: v, Raw: $1.Value()}
   }
   | FLOAT {
     l := yylex.(Lexer)
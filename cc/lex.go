@@ -194,6 +194,21 @@ func (s *lexer) scanDigit() scannedDigitType {
 	if !IsDigit(s.Peek()) {
 		return scannedDigitUnknown
 	}
+	if s.Peek() == '0' {
+		_ = s.Next()
+		if isDigitInBase := digitPredicateOfPrefix(s.Peek()); isDigitInBase != nil {
+			// Read integer with base prefix like 0x1f
+			_ = s.Next()
+			if !isDigitInBase(s.Peek()) {
+				s.errorf("invalid integer literal %s", s.Buffer())
+				return scannedDigitInt
+			}
+			for x := s.Peek(); isDigitInBase(x); x = s.Peek() {
+				_ = s.Next()
+			}
+			return scannedDigitInt
+		}
+	}
 	for x := s.Peek(); IsDigit(x); x = s.Peek() {
 		_ = s.Next()
 	}
@@ -377,6 +392,8 @@ func (s *lexer) Scan() int {
 		return ORDER
 	case "limit":
 		return LIMIT
+	case "prune":
+		return PRUNE
 	case "as":
 		return AS
 	case "asc":
@@ -114,6 +114,17 @@ func TestLexer(t *testing.T) {
 				token.New(cc.INT, "5"),
 			},
 		},
+		{
+			title: "integers with base prefix",
+			input: "0x1fF, 0o755 0b1010 010",
+			want: []token.Token{
+				token.New(cc.INT, "0x1fF"),
+				token.New(cc.COMMA, ","),
+				token.New(cc.INT, "0o755"),
+				token.New(cc.INT, "0b1010"),
+				token.New(cc.INT, "010"),
+			},
+		},
 		{
 			title: "ugly",
 			input: "SELECT size as Size,-   size As neG24   , Where  NORM( 1, 3,p)>0.5  ;",
@@ -168,3 +179,24 @@ func TestLexer(t *testing.T) {
 		})
 	}
 }
+
+func TestParseInt(t *testing.T) {
+	for _, tc := range []*struct {
+		input string
+		want  int
+	}{
+		{input: "10", want: 10},
+		{input: "010", want: 10},
+		{input: "0x1ff", want: 511},
+		{input: "0X1F", want: 31},
+		{input: "0o755", want: 493},
+		{input: "0b1010", want: 10},
+	} {
+		tc := tc
+		t.Run(tc.input, func(t *testing.T) {
+			got, err := cc.ParseInt(tc.input)
+			assert.Nil(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
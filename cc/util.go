@@ -8,10 +8,39 @@ import (
 
 func ParseFloat(x string) (float64, error) { return strconv.ParseFloat(x, 64) }
 
-func ParseInt(x string) (int, error) { return strconv.Atoi(x) }
+// ParseInt parses a string as an integer.
+// The string prefixed with 0x, 0o or 0b is parsed as hex, octal or binary digits.
+func ParseInt(x string) (int, error) {
+	if len(x) > 2 && x[0] == '0' && digitPredicateOfPrefix(rune(x[1])) != nil {
+		r, err := strconv.ParseInt(x, 0, 0)
+		return int(r), err
+	}
+	return strconv.Atoi(x)
+}
 
 func IsDigit(x rune) bool { return unicode.IsDigit(x) }
 
+func IsHexDigit(x rune) bool { return strings.ContainsRune("0123456789abcdefABCDEF", x) }
+
+func IsOctalDigit(x rune) bool { return '0' <= x && x <= '7' }
+
+func IsBinaryDigit(x rune) bool { return x == '0' || x == '1' }
+
+// digitPredicateOfPrefix returns the predicate of the digits of the base specified by the prefix of an integer literal.
+// Returns nil if x is not a base prefix.
+func digitPredicateOfPrefix(x rune) func(rune) bool {
+	switch x {
+	case 'x', 'X':
+		return IsHexDigit
+	case 'o', 'O':
+		return IsOctalDigit
+	case 'b', 'B':
+		return IsBinaryDigit
+	default:
+		return nil
+	}
+}
+
 func IsSpace(x rune) bool { return unicode.IsSpace(x) }
 
 func IsIdentTail(x rune) bool {
@@ -2,27 +2,79 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"os"
 	"os/signal"
+	"strconv"
 	"strings"
 
 	"github.com/berquerant/dql"
 	"github.com/berquerant/dql/ast"
 	"github.com/berquerant/dql/cc"
+	"github.com/berquerant/dql/dig"
 	"github.com/berquerant/dql/eval"
+	"github.com/berquerant/dql/function"
 	"github.com/berquerant/dql/logger"
 )
 
 var (
 	verbose   = flag.Int("v", -1, "Verbose logging level. Enable debug logs if not negative level.")
-	asJSON    = flag.Bool("j", false, "Print result as json.")
+	asJSON    = flag.Bool("json", false, "Print result as json.")
 	noHeaders = flag.Bool("H", false, "Print no header line.")
+	follow    = flag.Bool("L", false, "Follow symbolic links.")
+	keepGoing = flag.Bool("keep-going", false, "Skip the files that cannot be read instead of aborting.")
+	maxDepth  = flag.Int("maxdepth", -1, "Dig at most n levels below the targets. No limit if negative.")
+	minDepth  = flag.Int("mindepth", 0, "Print no files at levels less than n below the targets.")
+	xdev      = flag.Bool("xdev", false, "Dig no directories on other filesystems.")
+	noHidden  = flag.Bool("no-hidden", false, "Skip the files whose names start with a dot.")
+	walkers   = flag.Int("walkers", 1, "Number of goroutines to read files concurrently.")
+	unordered = flag.Bool("unordered", false, "Yield files in the order they are read with -walkers.")
+	bfs       = flag.Bool("bfs", false, "Dig breadth-first, yield the shallower files first.")
+	order     = flag.String("order", "name", "Order of the files in a directory. name, natural, mtime or size.")
+	orderDesc = flag.Bool("order-desc", false, "Reverse the order of -order.")
+	ignore    = flag.String("ignore", "none", "How to treat the files ignored by .gitignore, .ignore and .git/info/exclude. none, skip or mark.")
+	memBudget = flag.String("memory-budget", "0", "Approximate bytes of the rows kept in memory to sort or group, like 512M. The rest are written to temporary files. No limit if 0.")
+	tmpDir    = flag.String("tmpdir", "", "Directory to write temporary files. Default is the system temporary directory.")
+	schema    = flag.Bool("schema", false, "Print the columns of the result as json without running the query. The types are inferred statically.")
+	functions = flag.Bool("functions", false, "Print the builtin functions. As json with -json.")
+	excludes  stringsFlag
+	jobs      = jobsFlag(1)
 )
 
+func init() {
+	flag.Var(&excludes, "exclude", "Ignore the files matched with the glob pattern. Can be specified multiple times.")
+	flag.Var(&jobs, "j", "Evaluate the rows of WHERE and SELECT by `n` goroutines concurrently, e.g. for grep. The order of the rows is preserved.")
+}
+
+// stringsFlag is a flag that can be specified multiple times.
+type stringsFlag []string
+
+func (s *stringsFlag) String() string { return strings.Join(*s, ",") }
+func (s *stringsFlag) Set(v string) error {
+	*s = append(*s, v)
+	return nil
+}
+
+// jobsFlag is the number of the workers.
+// -j was the flag to print json, so Set tells to use -json if not a number.
+type jobsFlag int
+
+func (s *jobsFlag) String() string { return strconv.Itoa(int(*s)) }
+func (s *jobsFlag) Set(v string) error {
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return fmt.Errorf("want the number of goroutines, use -json to print json")
+	}
+	*s = jobsFlag(n)
+	return nil
+}
+
 const usage = `Usage of sql:
   dql QUERY files... directory...
+  dql -schema QUERY
+  dql -functions
 Flags:`
 
 func Usage() {
@@ -34,8 +86,15 @@ func Usage() {
 func main() {
 	flag.Usage = Usage
 	flag.Parse()
+	if *functions {
+		if err := printFunctions(); err != nil {
+			logger.Error("%v", err)
+			os.Exit(1)
+		}
+		return
+	}
 	args := flag.Args()
-	if len(args) < 2 {
+	if len(args) < 2 && !(*schema && len(args) == 1) {
 		flag.Usage()
 		os.Exit(2)
 	}
@@ -55,15 +114,157 @@ func main() {
 		os.Exit(1)
 	}
 	stmt := lexer.Result().(*ast.Statement)
+	if err := eval.Preprocess(stmt); err != nil {
+		logger.Error("%v", err)
+		os.Exit(1)
+	}
+	if err := eval.Check(stmt); err != nil {
+		if problems, ok := err.(eval.Problems); ok {
+			for _, p := range problems {
+				logger.Error("%s", p)
+			}
+		} else {
+			logger.Error("%v", err)
+		}
+		os.Exit(2)
+	}
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
-	err := printResult(ctx, eval.NewRunner(stmt), targets)
+	ignoreMode, err := parseIgnoreMode(*ignore)
+	if err != nil {
+		logger.Error("%v", err)
+		os.Exit(2)
+	}
+	childOrder, err := parseOrder(*order)
+	if err != nil {
+		logger.Error("%v", err)
+		os.Exit(2)
+	}
+	budget, err := parseBytes(*memBudget)
+	if err != nil {
+		logger.Error("%v", err)
+		os.Exit(2)
+	}
+	traversal := dig.DepthFirst
+	if *bfs {
+		traversal = dig.BreadthFirst
+	}
+	var (
+		skipped    int
+		digOptions = []dig.Option{
+			dig.WithFollowSymlinks(*follow),
+			dig.WithMaxDepth(*maxDepth),
+			dig.WithMinDepth(*minDepth),
+			dig.WithOneFileSystem(*xdev),
+			dig.WithExcludes(excludes...),
+			dig.WithIgnore(ignoreMode),
+			dig.WithSkipHidden(*noHidden),
+			dig.WithParallel(*walkers),
+			dig.WithUnordered(*unordered),
+			dig.WithTraversal(traversal),
+			dig.WithOrder(childOrder, *orderDesc),
+			dig.WithLazyStat(!eval.NeedsStat(stmt)),
+		}
+	)
+	if *keepGoing {
+		digOptions = append(digOptions, dig.WithSkipHandler(func(name string, err error) {
+			skipped++
+			logger.Error("skip %s %v", name, err)
+		}))
+	}
+	digger := dig.New(digOptions...)
+	runner := eval.NewRunner(stmt, digger,
+		eval.WithMemoryBudget(budget),
+		eval.WithTempDir(*tmpDir),
+		eval.WithWorkers(int(jobs)),
+	)
+	if *schema {
+		err = printSchema(runner)
+	} else {
+		err = printResult(ctx, runner, targets)
+	}
 	stop()
+	if skipped > 0 {
+		logger.Info("skipped %d paths", skipped)
+	}
 	if err != nil {
 		logger.Error("%v", err)
 		os.Exit(1)
 	}
 }
 
+func parseIgnoreMode(v string) (dig.IgnoreMode, error) {
+	switch v {
+	case "none":
+		return dig.IgnoreNone, nil
+	case "skip":
+		return dig.IgnoreSkip, nil
+	case "mark":
+		return dig.IgnoreMark, nil
+	default:
+		return dig.IgnoreNone, fmt.Errorf("unknown ignore mode %s", v)
+	}
+}
+
+func parseOrder(v string) (dig.Order, error) {
+	switch v {
+	case "name":
+		return dig.OrderName, nil
+	case "natural":
+		return dig.OrderNatural, nil
+	case "mtime":
+		return dig.OrderModTime, nil
+	case "size":
+		return dig.OrderSize, nil
+	default:
+		return dig.OrderName, fmt.Errorf("unknown order %s", v)
+	}
+}
+
+// parseBytes parses the number of bytes with an optional suffix K, M or G.
+func parseBytes(v string) (int, error) {
+	var (
+		x    = strings.ToUpper(v)
+		unit = 1
+	)
+	switch {
+	case strings.HasSuffix(x, "K"):
+		unit = 1 << 10
+	case strings.HasSuffix(x, "M"):
+		unit = 1 << 20
+	case strings.HasSuffix(x, "G"):
+		unit = 1 << 30
+	}
+	if unit > 1 {
+		x = x[:len(x)-1]
+	}
+	n, err := strconv.Atoi(x)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid bytes %s", v)
+	}
+	return n * unit, nil
+}
+
+func printSchema(runner eval.Runner) error {
+	b, err := json.Marshal(runner.Schema())
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s\n", b)
+	return nil
+}
+
+func printFunctions() error {
+	if !*asJSON {
+		return function.WriteTables(os.Stdout)
+	}
+	b, err := json.Marshal(function.Signatures())
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s\n", b)
+	return nil
+}
+
 func printResult(ctx context.Context, runner eval.Runner, targets []string) error {
 	if *asJSON {
 		return NewJSONWriter(runner, targets).Write(ctx, os.Stdout)
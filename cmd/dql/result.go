@@ -28,10 +28,10 @@ type jsonWriter struct {
 }
 
 func (s *jsonWriter) Write(ctx context.Context, w io.Writer) error {
-	for r := range s.runner.Run(ctx, s.targets...) {
-		if err := r.Err(); err != nil {
-			return err
-		}
+	cur := s.runner.Open(ctx, s.targets...)
+	defer cur.Close()
+	for cur.Next() {
+		r := cur.Row()
 		if len(s.runner.Headers()) != r.Len() {
 			return errors.New(fmt.Sprintf("%d headers but got row %d columns", len(s.runner.Headers()), r.Len()))
 		}
@@ -45,7 +45,7 @@ func (s *jsonWriter) Write(ctx context.Context, w io.Writer) error {
 		}
 		fmt.Fprintf(w, "%s\n", b)
 	}
-	return nil
+	return cur.Err()
 }
 
 func NewCSVWriter(runner eval.Runner, targets []string, noHeaders bool) ResultWriter {
@@ -69,10 +69,10 @@ func (s *csvWriter) Write(ctx context.Context, w io.Writer) error {
 			return errors.Wrap(err, "header")
 		}
 	}
-	for r := range s.runner.Run(ctx, s.targets...) {
-		if err := r.Err(); err != nil {
-			return err
-		}
+	cur := s.runner.Open(ctx, s.targets...)
+	defer cur.Close()
+	for cur.Next() {
+		r := cur.Row()
 		if len(s.runner.Headers()) != r.Len() {
 			return errors.New(fmt.Sprintf("%d headers but got row %d columns", len(s.runner.Headers()), r.Len()))
 		}
@@ -84,6 +84,9 @@ func (s *csvWriter) Write(ctx context.Context, w io.Writer) error {
 			return errors.Wrap(err, "row %#v", values)
 		}
 	}
+	if err := cur.Err(); err != nil {
+		return err
+	}
 	writer.Flush()
 	return writer.Error()
 }
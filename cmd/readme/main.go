@@ -0,0 +1,43 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/berquerant/dql/function"
+	"github.com/berquerant/dql/logger"
+)
+
+const usage = `Usage of readme:
+  readme README.md  # update the tables of the functions in README.md
+Flags:`
+
+func Usage() {
+	fmt.Fprintln(os.Stderr, usage)
+	flag.PrintDefaults()
+}
+
+func main() {
+	flag.Usage = Usage
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	name := flag.Arg(0)
+	doc, err := os.ReadFile(name)
+	if err != nil {
+		logger.Error("%v", err)
+		os.Exit(1)
+	}
+	r, err := function.UpdateDoc(string(doc))
+	if err != nil {
+		logger.Error("%v", err)
+		os.Exit(1)
+	}
+	if err := os.WriteFile(name, []byte(r), 0644); err != nil {
+		logger.Error("%v", err)
+		os.Exit(1)
+	}
+}
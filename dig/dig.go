@@ -5,10 +5,14 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
-	"sort"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/berquerant/dql/errors"
+	"github.com/berquerant/dql/fstat"
+	"github.com/berquerant/dql/glob"
+	"github.com/berquerant/dql/ignore"
 )
 
 type Instr int
@@ -33,58 +37,649 @@ type (
 		Mode() fs.FileMode
 		ModTime() time.Time
 		IsDir() bool
+		// Type returns the type bits of the mode, that is available without the stat.
+		Type() fs.FileMode
+		// Sys returns the underlying data source of the stat.
+		Sys() interface{}
+		// Root returns the search target as given to Dig.
+		Root() string
+		// RelName returns the path relative to the search target.
+		RelName() string
+		// IsSymlink returns true if the file is a symbolic link.
+		IsSymlink() bool
+		// LinkTarget returns the destination of the symbolic link.
+		// Returns an empty string if the file is not a symbolic link.
+		LinkTarget() string
+		// IsBrokenLink returns true if the file is a symbolic link whose destination does not exist.
+		IsBrokenLink() bool
+		// IsIgnored returns true if the file is ignored by the ignore files.
+		// Always false unless IgnoreMark.
+		IsIgnored() bool
 	}
 
 	fileInfo struct {
-		name string
-		stat fs.FileInfo
+		name    string
+		root    string
+		relName string
+		stat    fs.FileInfo
+		// typ is the type bits of the mode
+		typ fs.FileMode
+		// lazyStat stats the file on demand if not nil
+		lazyStat   *sync.Once
+		isSymlink  bool
+		linkTarget string
+		isBroken   bool
+		isIgnored  bool
 	}
 )
 
 // Override name.
 func (s *fileInfo) Name() string       { return s.name }
-func (s *fileInfo) Size() int64        { return s.stat.Size() }
-func (s *fileInfo) Mode() fs.FileMode  { return s.stat.Mode() }
-func (s *fileInfo) ModTime() time.Time { return s.stat.ModTime() }
-func (s *fileInfo) IsDir() bool        { return s.stat.IsDir() }
+func (s *fileInfo) Size() int64        { return s.fileStat().Size() }
+func (s *fileInfo) Mode() fs.FileMode  { return s.fileStat().Mode() }
+func (s *fileInfo) ModTime() time.Time { return s.fileStat().ModTime() }
+func (s *fileInfo) IsDir() bool        { return s.typ.IsDir() }
+func (s *fileInfo) Type() fs.FileMode  { return s.typ }
+func (s *fileInfo) Sys() interface{}   { return s.fileStat().Sys() }
+func (s *fileInfo) Root() string       { return s.root }
+func (s *fileInfo) RelName() string    { return s.relName }
+func (s *fileInfo) IsSymlink() bool    { return s.isSymlink }
+func (s *fileInfo) LinkTarget() string { return s.linkTarget }
+func (s *fileInfo) IsBrokenLink() bool { return s.isBroken }
+func (s *fileInfo) IsIgnored() bool    { return s.isIgnored }
+
+// fileStat returns the stat, stats the file on the first call if lazy.
+func (s *fileInfo) fileStat() fs.FileInfo {
+	if s.lazyStat != nil {
+		s.lazyStat.Do(func() {
+			stat, err := os.Lstat(s.name)
+			if err != nil {
+				stat = &typeStat{
+					name: filepath.Base(s.name),
+					typ:  s.typ,
+				}
+			}
+			s.stat = stat
+		})
+	}
+	return s.stat
+}
+
+// typeStat is the stat of the file that cannot be stat'ed lazily.
+// Only the type is available.
+type typeStat struct {
+	name string
+	typ  fs.FileMode
+}
+
+func (s *typeStat) Name() string      { return s.name }
+func (*typeStat) Size() int64         { return 0 }
+func (s *typeStat) Mode() fs.FileMode { return s.typ }
+func (*typeStat) ModTime() time.Time  { return time.Time{} }
+func (s *typeStat) IsDir() bool       { return s.typ.IsDir() }
+func (*typeStat) Sys() interface{}    { return nil }
 
 // Digger provides recursive file search operations.
 type Digger interface {
 	// Dig searches the information of a file or a directory recursively.
+	// The symbolic link given as name is followed.
 	Dig(name string, handler FileInfoHandler) error
 }
 
+// Option is an option of Digger.
+type Option func(*digger)
+
+// WithFollowSymlinks makes Digger follow symbolic links if v is true.
+// The directory that is an ancestor of itself is not dug.
+func WithFollowSymlinks(v bool) Option {
+	return func(s *digger) {
+		s.followSymlinks = v
+	}
+}
+
+// WithSkipHandler makes Digger skip the files that cannot be read instead of returning an error.
+// handler is called with the path and the error for each skipped file.
+func WithSkipHandler(handler func(name string, err error)) Option {
+	return func(s *digger) {
+		s.skipHandler = handler
+	}
+}
+
+// WithMaxDepth makes Digger dig at most n levels below the search target.
+// The search target itself is at depth 0. Negative n means no limit.
+func WithMaxDepth(n int) Option {
+	return func(s *digger) {
+		s.maxDepth = n
+	}
+}
+
+// WithMinDepth makes Digger pass no files at levels less than n to the handler.
+// The files are still dug.
+func WithMinDepth(n int) Option {
+	return func(s *digger) {
+		s.minDepth = n
+	}
+}
+
+// WithOneFileSystem makes Digger dig no directories on other filesystems than the search target if v is true.
+// The directories themselves are passed to the handler.
+func WithOneFileSystem(v bool) Option {
+	return func(s *digger) {
+		s.oneFileSystem = v
+	}
+}
+
+// WithExcludes makes Digger ignore the files matched with the glob patterns, see glob.Match.
+// A pattern with separators is matched against the path relative to the search target.
+// The search target itself is not excluded.
+func WithExcludes(patterns ...string) Option {
+	return func(s *digger) {
+		s.excludes = append(s.excludes, patterns...)
+	}
+}
+
+// WithSkipHidden makes Digger skip the files whose names start with a dot if v is true.
+// The descendants of the skipped directories are not dug.
+// The search target itself is not skipped.
+func WithSkipHidden(v bool) Option {
+	return func(s *digger) {
+		s.skipHidden = v
+	}
+}
+
+// IgnoreMode specifies how to treat the files ignored by the ignore files.
+type IgnoreMode int
+
+const (
+	// IgnoreNone reads no ignore files.
+	IgnoreNone IgnoreMode = iota
+	// IgnoreSkip skips the ignored files.
+	IgnoreSkip
+	// IgnoreMark passes the ignored files to the handler with IsIgnored true.
+	IgnoreMark
+)
+
+// IgnoreFiles are the ignore files read in each directory, the later file has the higher priority.
+var IgnoreFiles = []string{
+	".git/info/exclude",
+	".gitignore",
+	".ignore",
+}
+
+// WithLazyStat makes Digger stat the files only when their stats are read, if v is true.
+// The type of the directory entry is enough to dig unless the options like WithFollowSymlinks need the stats.
+// The stats are zero values if the file cannot be stat'ed then.
+func WithLazyStat(v bool) Option {
+	return func(s *digger) {
+		s.lazyStat = v
+	}
+}
+
+// WithIgnore makes Digger honor IgnoreFiles with the gitignore semantics.
+// The ignore files in the directories from the search target to the top of the git repository
+// containing the search target are also read.
+// The .git directories are always ignored.
+// The search target itself is not ignored.
+func WithIgnore(mode IgnoreMode) Option {
+	return func(s *digger) {
+		s.ignoreMode = mode
+	}
+}
+
 // New returns a new Digger.
-func New() Digger { return &digger{} }
+func New(opt ...Option) Digger {
+	s := &digger{
+		maxDepth: -1,
+	}
+	for _, o := range opt {
+		o(s)
+	}
+	if s.parallel > 1 {
+		return newParallelDigger(s)
+	}
+	return s
+}
 
-type digger struct{}
+type digger struct {
+	followSymlinks bool
+	skipHandler    func(name string, err error)
+	maxDepth       int
+	minDepth       int
+	oneFileSystem  bool
+	excludes       []string
+	ignoreMode     IgnoreMode
+	skipHidden     bool
+	parallel       int
+	unordered      bool
+	traversal      Traversal
+	order          Order
+	reverseOrder   bool
+	lazyStat       bool
+}
+
+// skipOrError returns nil if the error should be skipped.
+func (s *digger) skipOrError(name string, err error) error {
+	if s.skipHandler == nil {
+		return err
+	}
+	s.skipHandler(name, err)
+	return nil
+}
 
 func (s *digger) Dig(name string, handler FileInfoHandler) error {
-	p, err := filepath.Abs(name)
-	if err != nil {
-		return errors.Wrap(err, "digger dig %s", name)
+	t, root, err := s.root(name)
+	if err != nil || root == nil {
+		return err
+	}
+	if s.traversal == BreadthFirst {
+		err = s.digBreadthFirst(root, handler, func(n *node) ([]*node, error) {
+			return s.visitChildren(t, n)
+		})
+	} else {
+		err = s.dig(t, root, handler)
 	}
-	if err := s.dig(p, handler); err != nil && !errors.Is(err, errDone) {
+	if err != nil && !errors.Is(err, errDone) {
 		return err
 	}
 	return nil
 }
 
+// root returns the search target and its node.
+// Returns nil node if the search target should be skipped.
+func (s *digger) root(name string) (*target, *node, error) {
+	p, err := filepath.Abs(name)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "digger dig %s", name)
+	}
+	t := &target{
+		root:    name,
+		absRoot: p,
+	}
+	var scope *ignoreScope
+	if s.ignoreMode != IgnoreNone {
+		m, err := s.loadAncestorIgnores(p)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "digger dig %s", name)
+		}
+		scope = &ignoreScope{
+			matcher: m,
+		}
+	}
+	n, err := s.visit(t, nil, scope, &dirEntry{path: p}, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	return t, n, nil
+}
+
+// target is the search target of Dig.
+type target struct {
+	root    string
+	absRoot string
+	dev     uint64 // device number of the search target, if oneFileSystem
+}
+
+func (s *target) relName(name string) string {
+	r, err := filepath.Rel(s.absRoot, name)
+	if err != nil {
+		// unreachable: name is under absRoot
+		return name
+	}
+	return r
+}
+
+// ancestry is the directories from the search target to the parent of the digging file.
+type ancestry struct {
+	stat   fs.FileInfo
+	parent *ancestry
+}
+
+// contains returns true if stat is one of the ancestors.
+// The files are identified by os.SameFile, that compares device and inode numbers on unix.
+func (s *ancestry) contains(stat fs.FileInfo) bool {
+	for x := s; x != nil; x = x.parent {
+		if os.SameFile(x.stat, stat) {
+			return true
+		}
+	}
+	return false
+}
+
+// ignoreScope is the state of the ignore files of the directory.
+type ignoreScope struct {
+	matcher ignore.Matcher
+	ignored bool // true if the directory is ignored
+}
+
+// isIgnored returns true if the file in the directory is ignored.
+func (s *ignoreScope) isIgnored(name string, isDir bool) bool {
+	if s.ignored {
+		// cannot re-include the file if the parent directory is ignored
+		return true
+	}
+	if isDir && filepath.Base(name) == ".git" {
+		return true
+	}
+	if s.matcher == nil {
+		return false
+	}
+	ignored, _ := s.matcher.Match(name, isDir)
+	return ignored
+}
+
+// childIgnoreScope returns the scope of the children of the directory in the scope.
+func (s *digger) childIgnoreScope(scope *ignoreScope, name string, ignored bool) (*ignoreScope, error) {
+	if ignored {
+		return &ignoreScope{
+			matcher: scope.matcher,
+			ignored: true,
+		}, nil
+	}
+	m, err := ignore.Load(name, IgnoreFiles...)
+	if err != nil {
+		return nil, errors.Wrap(err, "digger cannot read ignore files in %s", name)
+	}
+	return &ignoreScope{
+		matcher: ignore.Chain(scope.matcher, m),
+	}, nil
+}
+
+// loadAncestorIgnores reads the ignore files in the ancestors of the search target
+// up to the top of the git repository.
+// Returns nil if the search target is not in a git repository or is the top.
+func (s *digger) loadAncestorIgnores(absRoot string) (ignore.Matcher, error) {
+	if isGitTop(absRoot) {
+		return nil, nil
+	}
+	var (
+		dirs  []string
+		found bool
+	)
+	for d := filepath.Dir(absRoot); ; d = filepath.Dir(d) {
+		dirs = append(dirs, d)
+		if isGitTop(d) {
+			found = true
+			break
+		}
+		if d == filepath.Dir(d) {
+			break
+		}
+	}
+	if !found {
+		return nil, nil
+	}
+	var m ignore.Matcher
+	for i := len(dirs) - 1; i >= 0; i-- {
+		x, err := ignore.Load(dirs[i], IgnoreFiles...)
+		if err != nil {
+			return nil, err
+		}
+		m = ignore.Chain(m, x)
+	}
+	return m, nil
+}
+
+func isGitTop(dir string) bool {
+	_, err := os.Lstat(filepath.Join(dir, ".git"))
+	return err == nil
+}
+
 var (
 	errDone = errors.New("dig done")
 )
 
-func (s *digger) dig(name string, handler FileInfoHandler) error {
-	stat, err := os.Stat(name)
+func (s *digger) stat(t *target, name string, follow bool) (*fileInfo, error) {
+	lstat, err := os.Lstat(name)
 	if err != nil {
-		return errors.Wrap(err, "digger cannot get stat of %s", name)
+		return nil, errors.Wrap(err, "digger cannot get stat of %s", name)
 	}
 	info := &fileInfo{
-		name: name,
-		stat: stat,
+		name:    name,
+		root:    t.root,
+		relName: t.relName(name),
+		stat:    lstat,
+		typ:     lstat.Mode().Type(),
+	}
+	if lstat.Mode()&fs.ModeSymlink == 0 {
+		return info, nil
+	}
+	info.isSymlink = true
+	if info.linkTarget, err = os.Readlink(name); err != nil {
+		return nil, errors.Wrap(err, "digger cannot read link %s", name)
+	}
+	stat, err := os.Stat(name)
+	if err != nil {
+		info.isBroken = true
+		return info, nil
+	}
+	if follow {
+		info.stat = stat
+		info.typ = stat.Mode().Type()
+	}
+	return info, nil
+}
+
+// canStatLazily returns true if Digger does not need the stats of the files except for symbolic links.
+func (s *digger) canStatLazily() bool {
+	return s.lazyStat && !s.followSymlinks && !s.oneFileSystem && !s.needsStatToOrder()
+}
+
+// lazyStatInfo returns the info of the file that stats the file on demand.
+// typ is the type bits of the directory entry.
+func (s *digger) lazyStatInfo(t *target, name string, typ fs.FileMode) *fileInfo {
+	return &fileInfo{
+		name:     name,
+		root:     t.root,
+		relName:  t.relName(name),
+		typ:      typ,
+		lazyStat: &sync.Once{},
+	}
+}
+
+// isExcluded returns true if the file should be ignored.
+func (s *digger) isExcluded(t *target, name string) (bool, error) {
+	relName := t.relName(name)
+	for _, p := range s.excludes {
+		ok, err := glob.Match(p, relName)
+		if err != nil {
+			return false, errors.Wrap(err, "digger invalid exclude pattern %s", p)
+		}
+		if ok {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// isOtherFileSystem returns true if the file is not on the filesystem of the search target.
+func (s *digger) isOtherFileSystem(t *target, info *fileInfo) bool {
+	if !s.oneFileSystem {
+		return false
+	}
+	st, ok := fstat.FromFileInfo(info.stat)
+	return ok && st.Dev != t.dev
+}
+
+// node is a file to be dug.
+type node struct {
+	info  *fileInfo
+	depth int
+	// ancestors of the file
+	parent *ancestry
+	// scope of the directory containing the file
+	scope *ignoreScope
+}
+
+// visit stats the file and returns the node.
+// Returns nil if the file should be skipped.
+func (s *digger) visit(t *target, parent *ancestry, scope *ignoreScope, entry *dirEntry, depth int) (*node, error) {
+	var (
+		name   = entry.path
+		isRoot = depth == 0
+	)
+	if !isRoot && s.skipHidden && strings.HasPrefix(filepath.Base(name), ".") {
+		return nil, nil
+	}
+	if !isRoot && len(s.excludes) > 0 {
+		excluded, err := s.isExcluded(t, name)
+		if err != nil {
+			return nil, err
+		}
+		if excluded {
+			return nil, nil
+		}
+	}
+	var info *fileInfo
+	if !isRoot && entry.typ&fs.ModeSymlink == 0 && s.canStatLazily() {
+		info = s.lazyStatInfo(t, name, entry.typ)
+	} else {
+		var err error
+		if info, err = s.stat(t, name, s.followSymlinks || isRoot); err != nil {
+			return nil, s.skipOrError(name, err)
+		}
+	}
+	if scope != nil && !isRoot {
+		info.isIgnored = scope.isIgnored(name, info.IsDir())
+		if info.isIgnored && s.ignoreMode == IgnoreSkip {
+			return nil, nil
+		}
+	}
+	if isRoot && s.oneFileSystem {
+		if st, ok := fstat.FromFileInfo(info.stat); ok {
+			t.dev = st.Dev
+		}
+	}
+	return &node{
+		info:   info,
+		depth:  depth,
+		parent: parent,
+		scope:  scope,
+	}, nil
+}
+
+// call invokes the handler unless the file is shallower than minDepth.
+func (s *digger) call(n *node, handler FileInfoHandler) Instr {
+	if n.depth < s.minDepth {
+		return InstrContinue
+	}
+	return handler(n.info)
+}
+
+// dirEntry is a child of a directory.
+type dirEntry struct {
+	path string
+	// typ is the type bits of the entry
+	typ fs.FileMode
+}
+
+// children returns the children of the directory sorted by sortEntries,
+// and the ancestry and the ignore scope for them.
+// Returns no entries if the children should not be dug.
+func (s *digger) children(t *target, n *node) ([]*dirEntry, *ancestry, *ignoreScope, error) {
+	if !n.info.IsDir() {
+		return nil, nil, nil, nil
+	}
+	if s.maxDepth >= 0 && n.depth >= s.maxDepth {
+		return nil, nil, nil, nil
+	}
+	if s.isOtherFileSystem(t, n.info) {
+		// do not cross the filesystem boundary
+		return nil, nil, nil, nil
+	}
+	if s.followSymlinks && n.parent.contains(n.info.stat) {
+		// avoid the loop by symbolic links
+		return nil, nil, nil, nil
+	}
+	name := n.info.name
+	current := n.parent
+	if s.followSymlinks {
+		current = &ancestry{
+			stat:   n.info.stat,
+			parent: n.parent,
+		}
+	}
+	var scope *ignoreScope
+	if n.scope != nil {
+		var err error
+		if scope, err = s.childIgnoreScope(n.scope, name, n.info.isIgnored); err != nil {
+			if err := s.skipOrError(name, err); err != nil {
+				return nil, nil, nil, err
+			}
+			// dig without the ignore files of the directory
+			scope = n.scope
+		}
+	}
+	dir, err := os.Open(name)
+	if err != nil {
+		return nil, nil, nil, s.skipOrError(name, errors.Wrap(err, "digger cannot open directory %s", name))
+	}
+	defer dir.Close()
+	xs, err := dir.ReadDir(0)
+	if err != nil {
+		// dig the children read before the error when skipping
+		if err := s.skipOrError(name, errors.Wrap(err, "digger cannot read children of %s", name)); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+	entries := make([]*dirEntry, len(xs))
+	for i, x := range xs {
+		entries[i] = &dirEntry{
+			path: filepath.Join(name, x.Name()),
+			typ:  x.Type(),
+		}
 	}
-	instr := handler(info)
-	switch instr {
+	s.sortEntries(entries)
+	return entries, current, scope, nil
+}
+
+// visitChildren stats the children of the directory and returns the nodes in order.
+func (s *digger) visitChildren(t *target, n *node) ([]*node, error) {
+	entries, current, scope, err := s.children(t, n)
+	if err != nil {
+		return nil, err
+	}
+	nodes := make([]*node, 0, len(entries))
+	for _, e := range entries {
+		c, err := s.visit(t, current, scope, e, n.depth+1)
+		if err != nil {
+			return nil, err
+		}
+		if c != nil {
+			nodes = append(nodes, c)
+		}
+	}
+	s.sortNodes(nodes)
+	return nodes, nil
+}
+
+// digBreadthFirst passes the files to the handler level by level.
+// visitChildren returns the children of the directory in order.
+func (s *digger) digBreadthFirst(root *node, handler FileInfoHandler, visitChildren func(*node) ([]*node, error)) error {
+	queue := []*node{root}
+	for len(queue) > 0 {
+		n := queue[0]
+		queue[0] = nil
+		queue = queue[1:]
+		switch instr := s.call(n, handler); instr {
+		case InstrCancel:
+			return errDone
+		case InstrSkipDir:
+			continue
+		case InstrContinue:
+			nodes, err := visitChildren(n)
+			if err != nil {
+				return err
+			}
+			queue = append(queue, nodes...)
+		default:
+			panic(fmt.Sprintf("dig encountered unknown Instr %s", instr))
+		}
+	}
+	return nil
+}
+
+func (s *digger) dig(t *target, n *node, handler FileInfoHandler) error {
+	switch instr := s.call(n, handler); instr {
 	case InstrCancel:
 		// cancel dig invocations.
 		return errDone
@@ -93,25 +688,31 @@ func (s *digger) dig(name string, handler FileInfoHandler) error {
 		return nil
 	case InstrContinue:
 		// dig the children of the directory.
-		if !info.IsDir() {
+		if s.needsStatToOrder() {
+			nodes, err := s.visitChildren(t, n)
+			if err != nil {
+				return err
+			}
+			for _, c := range nodes {
+				if err := s.dig(t, c, handler); err != nil {
+					return err
+				}
+			}
 			return nil
 		}
-		dir, err := os.Open(name)
+		entries, current, scope, err := s.children(t, n)
 		if err != nil {
-			return errors.Wrap(err, "digger cannot open directory %s", name)
+			return err
 		}
-		defer dir.Close()
-		children, err := dir.Readdirnames(0)
-		if err != nil {
-			return errors.Wrap(err, "digger cannot read children of %s", name)
-		}
-		sort.Strings(children)
-		for _, c := range children {
-			p := filepath.Join(name, c)
-			if err := s.dig(p, handler); err != nil {
-				if errors.Is(err, errDone) {
-					return nil
-				}
+		for _, e := range entries {
+			c, err := s.visit(t, current, scope, e, n.depth+1)
+			if err != nil {
+				return err
+			}
+			if c == nil {
+				continue
+			}
+			if err := s.dig(t, c, handler); err != nil {
 				return err
 			}
 		}
@@ -1,9 +1,12 @@
 package dig_test
 
 import (
+	"io/fs"
 	"os"
 	"path/filepath"
+	"sort"
 	"testing"
+	"time"
 
 	"github.com/berquerant/dql/dig"
 	"github.com/stretchr/testify/assert"
@@ -112,3 +115,695 @@ func TestDigger(t *testing.T) {
 		})
 	}
 }
+
+func TestDiggerRoot(t *testing.T) {
+	root := filepath.Join(os.Getenv("ROOT"), "dig", "testdata")
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(root); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	type result struct {
+		root    string
+		relName string
+	}
+	got := []result{}
+	err = dig.New().Dig("dir2", func(info dig.FileInfo) dig.Instr {
+		got = append(got, result{
+			root:    info.Root(),
+			relName: info.RelName(),
+		})
+		return dig.InstrContinue
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []result{
+		{root: "dir2", relName: "."},
+		{root: "dir2", relName: "c.log"},
+		{root: "dir2", relName: "d.log"},
+	}, got)
+}
+
+func TestDiggerSymlink(t *testing.T) {
+	root := t.TempDir()
+	for _, x := range []struct {
+		name   string
+		target string // symlink if not empty
+		isDir  bool
+	}{
+		{name: "a"},
+		{name: "dir", isDir: true},
+		{name: "dir/b"},
+		{name: "dir/loop", target: ".."},
+		{name: "dangling", target: "not_exist"},
+		{name: "link", target: "dir"},
+	} {
+		p := filepath.Join(root, x.name)
+		var err error
+		switch {
+		case x.target != "":
+			err = os.Symlink(x.target, p)
+		case x.isDir:
+			err = os.Mkdir(p, 0755)
+		default:
+			err = os.WriteFile(p, []byte(x.name), 0644)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	type result struct {
+		relName    string
+		isDir      bool
+		isSymlink  bool
+		linkTarget string
+		isBroken   bool
+	}
+	for _, tc := range []*struct {
+		title string
+		opt   []dig.Option
+		want  []result
+	}{
+		{
+			title: "lstat",
+			want: []result{
+				{relName: ".", isDir: true},
+				{relName: "a"},
+				{relName: "dangling", isSymlink: true, linkTarget: "not_exist", isBroken: true},
+				{relName: "dir", isDir: true},
+				{relName: "dir/b"},
+				{relName: "dir/loop", isSymlink: true, linkTarget: ".."},
+				{relName: "link", isSymlink: true, linkTarget: "dir"},
+			},
+		},
+		{
+			title: "follow",
+			opt:   []dig.Option{dig.WithFollowSymlinks(true)},
+			want: []result{
+				{relName: ".", isDir: true},
+				{relName: "a"},
+				{relName: "dangling", isSymlink: true, linkTarget: "not_exist", isBroken: true},
+				{relName: "dir", isDir: true},
+				{relName: "dir/b"},
+				{relName: "dir/loop", isDir: true, isSymlink: true, linkTarget: ".."},
+				{relName: "link", isDir: true, isSymlink: true, linkTarget: "dir"},
+				{relName: "link/b"},
+				{relName: "link/loop", isDir: true, isSymlink: true, linkTarget: ".."},
+			},
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			got := []result{}
+			err := dig.New(tc.opt...).Dig(root, func(info dig.FileInfo) dig.Instr {
+				got = append(got, result{
+					relName:    info.RelName(),
+					isDir:      info.IsDir(),
+					isSymlink:  info.IsSymlink(),
+					linkTarget: info.LinkTarget(),
+					isBroken:   info.IsBrokenLink(),
+				})
+				return dig.InstrContinue
+			})
+			assert.Nil(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestDiggerSkip(t *testing.T) {
+	prepare := func(t *testing.T) string {
+		root := t.TempDir()
+		for _, d := range []string{"dir", "dir/sub"} {
+			if err := os.Mkdir(filepath.Join(root, d), 0755); err != nil {
+				t.Fatal(err)
+			}
+		}
+		for _, f := range []string{"a", "b", "dir/c"} {
+			if err := os.WriteFile(filepath.Join(root, f), []byte(f), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return root
+	}
+	// removeOnVisit removes the files while digging to make digger fail to read them.
+	removeOnVisit := func(root string, got *[]string) dig.FileInfoHandler {
+		return func(v dig.FileInfo) dig.Instr {
+			*got = append(*got, v.RelName())
+			switch v.RelName() {
+			case "a":
+				_ = os.Remove(filepath.Join(root, "b"))
+			case "dir":
+				_ = os.RemoveAll(v.Name())
+			}
+			return dig.InstrContinue
+		}
+	}
+
+	t.Run("abort", func(t *testing.T) {
+		root := prepare(t)
+		got := []string{}
+		err := dig.New().Dig(root, removeOnVisit(root, &got))
+		assert.NotNil(t, err)
+		assert.Equal(t, []string{".", "a"}, got)
+	})
+
+	t.Run("skip", func(t *testing.T) {
+		root := prepare(t)
+		var (
+			got     = []string{}
+			skipped = []string{}
+		)
+		err := dig.New(dig.WithSkipHandler(func(name string, err error) {
+			assert.NotNil(t, err)
+			r, _ := filepath.Rel(root, name)
+			skipped = append(skipped, r)
+		})).Dig(root, removeOnVisit(root, &got))
+		assert.Nil(t, err)
+		assert.Equal(t, []string{".", "a", "dir"}, got)
+		assert.Equal(t, []string{"b", "dir"}, skipped)
+	})
+
+	t.Run("skip target", func(t *testing.T) {
+		var skipped []string
+		err := dig.New(dig.WithSkipHandler(func(name string, _ error) {
+			skipped = append(skipped, filepath.Base(name))
+		})).Dig(filepath.Join(t.TempDir(), "not_exist"), func(dig.FileInfo) dig.Instr {
+			return dig.InstrContinue
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"not_exist"}, skipped)
+	})
+}
+
+func TestDiggerFilter(t *testing.T) {
+	root := t.TempDir()
+	for _, d := range []string{"a", "a/b", "a/b/c", "vendor"} {
+		if err := os.Mkdir(filepath.Join(root, d), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, f := range []string{"x.go", "a/y.go", "a/b/z.txt", "a/b/c/w.go", "vendor/v.go"} {
+		if err := os.WriteFile(filepath.Join(root, f), []byte(f), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, tc := range []*struct {
+		title string
+		opt   []dig.Option
+		want  []string
+		isErr bool
+	}{
+		{
+			title: "no options",
+			want:  []string{".", "a", "a/b", "a/b/c", "a/b/c/w.go", "a/b/z.txt", "a/y.go", "vendor", "vendor/v.go", "x.go"},
+		},
+		{
+			title: "max depth 0",
+			opt:   []dig.Option{dig.WithMaxDepth(0)},
+			want:  []string{"."},
+		},
+		{
+			title: "max depth 2",
+			opt:   []dig.Option{dig.WithMaxDepth(2)},
+			want:  []string{".", "a", "a/b", "a/y.go", "vendor", "vendor/v.go", "x.go"},
+		},
+		{
+			title: "min depth 2",
+			opt:   []dig.Option{dig.WithMinDepth(2)},
+			want:  []string{"a/b", "a/b/c", "a/b/c/w.go", "a/b/z.txt", "a/y.go", "vendor/v.go"},
+		},
+		{
+			title: "min depth and max depth",
+			opt:   []dig.Option{dig.WithMinDepth(2), dig.WithMaxDepth(2)},
+			want:  []string{"a/b", "a/y.go", "vendor/v.go"},
+		},
+		{
+			title: "exclude base name",
+			opt:   []dig.Option{dig.WithExcludes("vendor", "*.txt")},
+			want:  []string{".", "a", "a/b", "a/b/c", "a/b/c/w.go", "a/y.go", "x.go"},
+		},
+		{
+			title: "exclude relative path",
+			opt:   []dig.Option{dig.WithExcludes("a/b")},
+			want:  []string{".", "a", "a/y.go", "vendor", "vendor/v.go", "x.go"},
+		},
+		{
+			title: "one file system",
+			opt:   []dig.Option{dig.WithOneFileSystem(true), dig.WithMaxDepth(1)},
+			want:  []string{".", "a", "vendor", "x.go"},
+		},
+		{
+			title: "invalid exclude",
+			opt:   []dig.Option{dig.WithExcludes("[")},
+			isErr: true,
+		},
+	} {
+		tc := tc
+		for _, m := range digModes {
+			m := m
+			t.Run(tc.title+" "+m.title, func(t *testing.T) {
+				got := []string{}
+				err := dig.New(append(tc.opt, m.opt...)...).Dig(root, func(v dig.FileInfo) dig.Instr {
+					got = append(got, v.RelName())
+					return dig.InstrContinue
+				})
+				if tc.isErr {
+					assert.NotNil(t, err)
+					return
+				}
+				assert.Nil(t, err)
+				if m.unordered {
+					sort.Strings(got)
+				}
+				assert.Equal(t, tc.want, got)
+			})
+		}
+	}
+}
+
+// digModes are the options to test the Digger implementations.
+var digModes = []struct {
+	title     string
+	opt       []dig.Option
+	unordered bool
+}{
+	{
+		title: "sequential",
+	},
+	{
+		title: "parallel",
+		opt:   []dig.Option{dig.WithParallel(4)},
+	},
+	{
+		title:     "parallel unordered",
+		opt:       []dig.Option{dig.WithParallel(4), dig.WithUnordered(true)},
+		unordered: true,
+	},
+}
+
+func TestDiggerInstr(t *testing.T) {
+	root := t.TempDir()
+	for _, d := range []string{"a", "a/b", "c"} {
+		if err := os.Mkdir(filepath.Join(root, d), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, f := range []string{"a/b/x", "a/y", "c/z", "d"} {
+		if err := os.WriteFile(filepath.Join(root, f), []byte(f), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, m := range digModes {
+		m := m
+		t.Run(m.title, func(t *testing.T) {
+			t.Run("skip dir", func(t *testing.T) {
+				got := []string{}
+				err := dig.New(m.opt...).Dig(root, func(v dig.FileInfo) dig.Instr {
+					got = append(got, v.RelName())
+					if v.RelName() == "a" {
+						return dig.InstrSkipDir
+					}
+					return dig.InstrContinue
+				})
+				assert.Nil(t, err)
+				sort.Strings(got)
+				assert.Equal(t, []string{".", "a", "c", "c/z", "d"}, got)
+			})
+
+			t.Run("cancel", func(t *testing.T) {
+				got := []string{}
+				err := dig.New(m.opt...).Dig(root, func(v dig.FileInfo) dig.Instr {
+					got = append(got, v.RelName())
+					if v.RelName() == "a/b/x" {
+						return dig.InstrCancel
+					}
+					return dig.InstrContinue
+				})
+				assert.Nil(t, err)
+				if m.unordered {
+					// the files after a/b/x are not visited
+					assert.Contains(t, got, "a/b/x")
+					assert.Equal(t, "a/b/x", got[len(got)-1])
+					return
+				}
+				assert.Equal(t, []string{".", "a", "a/b", "a/b/x"}, got)
+			})
+		})
+	}
+}
+
+func TestDiggerOrder(t *testing.T) {
+	root := t.TempDir()
+	for _, d := range []string{"d10", "d2"} {
+		if err := os.Mkdir(filepath.Join(root, d), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	now := time.Now()
+	for i, f := range []struct {
+		name string
+		size int
+	}{
+		{name: "d10/x", size: 3},
+		{name: "d2/y", size: 1},
+		{name: "f1", size: 2},
+		{name: "f01", size: 4},
+		{name: "f10", size: 0},
+		{name: "f9", size: 2},
+	} {
+		name := filepath.Join(root, f.name)
+		if err := os.WriteFile(name, make([]byte, f.size), 0644); err != nil {
+			t.Fatal(err)
+		}
+		// the later file is older
+		mtime := now.Add(-time.Duration(i) * time.Hour)
+		if err := os.Chtimes(name, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i, d := range []string{"d10", "d2"} {
+		mtime := now.Add(time.Duration(i+1) * time.Hour)
+		if err := os.Chtimes(filepath.Join(root, d), mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, tc := range []*struct {
+		title   string
+		opt     []dig.Option
+		handler dig.FileInfoHandler
+		want    []string
+	}{
+		{
+			title: "name",
+			want:  []string{".", "d10", "d10/x", "d2", "d2/y", "f01", "f1", "f10", "f9"},
+		},
+		{
+			title: "natural",
+			opt:   []dig.Option{dig.WithOrder(dig.OrderNatural, false)},
+			want:  []string{".", "d2", "d2/y", "d10", "d10/x", "f01", "f1", "f9", "f10"},
+		},
+		{
+			title: "natural reverse",
+			opt:   []dig.Option{dig.WithOrder(dig.OrderNatural, true)},
+			want:  []string{".", "f10", "f9", "f1", "f01", "d10", "d10/x", "d2", "d2/y"},
+		},
+		{
+			title: "mod time",
+			opt:   []dig.Option{dig.WithOrder(dig.OrderModTime, false)},
+			want:  []string{".", "f9", "f10", "f01", "f1", "d10", "d10/x", "d2", "d2/y"},
+		},
+		{
+			title: "mod time reverse",
+			opt:   []dig.Option{dig.WithOrder(dig.OrderModTime, true)},
+			want:  []string{".", "d2", "d2/y", "d10", "d10/x", "f1", "f01", "f10", "f9"},
+		},
+		{
+			title: "size ties by name",
+			opt:   []dig.Option{dig.WithOrder(dig.OrderSize, false), dig.WithMaxDepth(1)},
+			want:  []string{".", "f10", "f1", "f9", "f01", "d10", "d2"},
+		},
+		{
+			title: "breadth first",
+			opt:   []dig.Option{dig.WithTraversal(dig.BreadthFirst)},
+			want:  []string{".", "d10", "d2", "f01", "f1", "f10", "f9", "d10/x", "d2/y"},
+		},
+		{
+			title: "breadth first natural",
+			opt:   []dig.Option{dig.WithTraversal(dig.BreadthFirst), dig.WithOrder(dig.OrderNatural, false)},
+			want:  []string{".", "d2", "d10", "f01", "f1", "f9", "f10", "d2/y", "d10/x"},
+		},
+		{
+			title: "breadth first skip dir",
+			opt:   []dig.Option{dig.WithTraversal(dig.BreadthFirst)},
+			handler: func(v dig.FileInfo) dig.Instr {
+				if v.RelName() == "d10" {
+					return dig.InstrSkipDir
+				}
+				return dig.InstrContinue
+			},
+			want: []string{".", "d10", "d2", "f01", "f1", "f10", "f9", "d2/y"},
+		},
+		{
+			title: "breadth first cancel",
+			opt:   []dig.Option{dig.WithTraversal(dig.BreadthFirst)},
+			handler: func(v dig.FileInfo) dig.Instr {
+				if v.RelName() == "f1" {
+					return dig.InstrCancel
+				}
+				return dig.InstrContinue
+			},
+			want: []string{".", "d10", "d2", "f01", "f1"},
+		},
+	} {
+		tc := tc
+		for _, m := range digModes {
+			if m.unordered {
+				continue
+			}
+			m := m
+			t.Run(tc.title+" "+m.title, func(t *testing.T) {
+				got := []string{}
+				err := dig.New(append(tc.opt, m.opt...)...).Dig(root, func(v dig.FileInfo) dig.Instr {
+					got = append(got, v.RelName())
+					if tc.handler != nil {
+						return tc.handler(v)
+					}
+					return dig.InstrContinue
+				})
+				assert.Nil(t, err)
+				assert.Equal(t, tc.want, got)
+			})
+		}
+	}
+}
+
+func TestDiggerIgnore(t *testing.T) {
+	top := t.TempDir()
+	for _, d := range []string{".git", ".git/info", "src", "src/build", "src/vendor", "out"} {
+		if err := os.Mkdir(filepath.Join(top, d), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for name, content := range map[string]string{
+		".git/HEAD":         "",
+		".git/info/exclude": "*.swp\n",
+		".gitignore":        "/out/\n*.log\n!keep.log\n",
+		"a.log":             "",
+		"keep.log":          "",
+		"a.swp":             "",
+		"out/x":             "",
+		"src/.ignore":       "vendor/\n",
+		"src/.gitignore":    "build\n!b.log\n",
+		"src/a.go":          "",
+		"src/b.log":         "",
+		"src/build/y":       "",
+		"src/vendor/z.go":   "",
+	} {
+		if err := os.WriteFile(filepath.Join(top, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	type result struct {
+		relName   string
+		isIgnored bool
+	}
+	digWithIgnore := func(t *testing.T, target string, mode dig.IgnoreMode) []result {
+		got := []result{}
+		err := dig.New(dig.WithIgnore(mode)).Dig(target, func(v dig.FileInfo) dig.Instr {
+			got = append(got, result{
+				relName:   v.RelName(),
+				isIgnored: v.IsIgnored(),
+			})
+			return dig.InstrContinue
+		})
+		assert.Nil(t, err)
+		return got
+	}
+
+	t.Run("skip", func(t *testing.T) {
+		assert.Equal(t, []result{
+			{relName: "."},
+			{relName: ".gitignore"},
+			{relName: "keep.log"},
+			{relName: "src"},
+			{relName: "src/.gitignore"},
+			{relName: "src/.ignore"},
+			{relName: "src/a.go"},
+			{relName: "src/b.log"},
+		}, digWithIgnore(t, top, dig.IgnoreSkip))
+	})
+
+	t.Run("skip in subdirectory", func(t *testing.T) {
+		assert.Equal(t, []result{
+			{relName: "."},
+			{relName: ".gitignore"},
+			{relName: ".ignore"},
+			{relName: "a.go"},
+			{relName: "b.log"},
+		}, digWithIgnore(t, filepath.Join(top, "src"), dig.IgnoreSkip))
+	})
+
+	t.Run("mark", func(t *testing.T) {
+		assert.Equal(t, []result{
+			{relName: "."},
+			{relName: ".git", isIgnored: true},
+			{relName: ".git/HEAD", isIgnored: true},
+			{relName: ".git/info", isIgnored: true},
+			{relName: ".git/info/exclude", isIgnored: true},
+			{relName: ".gitignore"},
+			{relName: "a.log", isIgnored: true},
+			{relName: "a.swp", isIgnored: true},
+			{relName: "keep.log"},
+			{relName: "out", isIgnored: true},
+			{relName: "out/x", isIgnored: true},
+			{relName: "src"},
+			{relName: "src/.gitignore"},
+			{relName: "src/.ignore"},
+			{relName: "src/a.go"},
+			{relName: "src/b.log"},
+			{relName: "src/build", isIgnored: true},
+			{relName: "src/build/y", isIgnored: true},
+			{relName: "src/vendor", isIgnored: true},
+			{relName: "src/vendor/z.go", isIgnored: true},
+		}, digWithIgnore(t, top, dig.IgnoreMark))
+	})
+}
+
+func TestDiggerSkipHidden(t *testing.T) {
+	root := filepath.Join(t.TempDir(), ".root")
+	for _, d := range []string{"", "a", ".b"} {
+		if err := os.Mkdir(filepath.Join(root, d), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, f := range []string{".x", "a/y", "a/.z", ".b/w"} {
+		if err := os.WriteFile(filepath.Join(root, f), []byte(f), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got := []string{}
+	err := dig.New(dig.WithSkipHidden(true)).Dig(root, func(v dig.FileInfo) dig.Instr {
+		got = append(got, v.RelName())
+		return dig.InstrContinue
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{".", "a", "a/y"}, got)
+}
+
+func TestDiggerLazyStat(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "d", "e"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range []string{"a", "d/b", "d/e/c"} {
+		if err := os.WriteFile(filepath.Join(root, f), []byte(f), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink("d", filepath.Join(root, "l")); err != nil {
+		t.Fatal(err)
+	}
+	write := func(content string) {
+		if err := os.WriteFile(filepath.Join(root, "d", "b"), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("b")
+
+	type result struct {
+		relName string
+		isDir   bool
+		typ     fs.FileMode
+		size    int64
+		mode    fs.FileMode
+	}
+	var (
+		collect = func(opt ...dig.Option) []*result {
+			var results []*result
+			err := dig.New(opt...).Dig(root, func(v dig.FileInfo) dig.Instr {
+				results = append(results, &result{
+					relName: v.RelName(),
+					isDir:   v.IsDir(),
+					typ:     v.Type(),
+					size:    v.Size(),
+					mode:    v.Mode(),
+				})
+				return dig.InstrContinue
+			})
+			assert.Nil(t, err)
+			return results
+		}
+		// infos returns the infos without reading the stats
+		infos = func(opt ...dig.Option) []dig.FileInfo {
+			var infos []dig.FileInfo
+			err := dig.New(opt...).Dig(root, func(v dig.FileInfo) dig.Instr {
+				infos = append(infos, v)
+				return dig.InstrContinue
+			})
+			assert.Nil(t, err)
+			return infos
+		}
+		sizes = func(infos []dig.FileInfo) map[string]int64 {
+			d := map[string]int64{}
+			for _, v := range infos {
+				if !v.IsDir() {
+					d[v.RelName()] = v.Size()
+				}
+			}
+			return d
+		}
+	)
+
+	for _, tc := range []*struct {
+		title string
+		opt   []dig.Option
+		// lazy is true if the stats are read on demand
+		lazy bool
+	}{
+		{
+			title: "lazy",
+			lazy:  true,
+		},
+		{
+			title: "lazy parallel",
+			opt:   []dig.Option{dig.WithParallel(4)},
+			lazy:  true,
+		},
+		{
+			title: "follow symlinks",
+			opt:   []dig.Option{dig.WithFollowSymlinks(true)},
+		},
+		{
+			title: "order by size",
+			opt:   []dig.Option{dig.WithOrder(dig.OrderSize, false)},
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			opt := append([]dig.Option{dig.WithLazyStat(true)}, tc.opt...)
+			want := collect(tc.opt...)
+			got := collect(opt...)
+			assert.Equal(t, want, got)
+
+			infos := infos(opt...)
+			write("bbb")
+			defer write("b")
+			wantSize := int64(1)
+			if tc.lazy {
+				// stat after the change
+				wantSize = 3
+			}
+			assert.Equal(t, wantSize, sizes(infos)["d/b"])
+			assert.Equal(t, int64(5), sizes(infos)["d/e/c"])
+		})
+	}
+}
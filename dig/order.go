@@ -0,0 +1,138 @@
+package dig
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Traversal is the order of the directories to dig.
+type Traversal int
+
+const (
+	// DepthFirst digs the children of a directory before its siblings.
+	DepthFirst Traversal = iota
+	// BreadthFirst digs the shallower files first.
+	BreadthFirst
+)
+
+// Order is the order of the children of a directory.
+type Order int
+
+const (
+	// OrderName sorts the children by name.
+	OrderName Order = iota
+	// OrderNatural sorts the children by name, comparing the digits in names as numbers, e.g. a2 < a10.
+	OrderNatural
+	// OrderModTime sorts the children by modification time, older first.
+	OrderModTime
+	// OrderSize sorts the children by size, smaller first.
+	OrderSize
+)
+
+// WithTraversal sets the order of the directories to dig.
+// No effect with WithUnordered.
+func WithTraversal(traversal Traversal) Option {
+	return func(s *digger) {
+		s.traversal = traversal
+	}
+}
+
+// WithOrder sets the order of the children of a directory, reversed if reverse is true.
+// The ties are sorted by name.
+// No effect with WithUnordered.
+func WithOrder(order Order, reverse bool) Option {
+	return func(s *digger) {
+		s.order = order
+		s.reverseOrder = reverse
+	}
+}
+
+// needsStatToOrder returns true if the order requires the stats of the children.
+func (s *digger) needsStatToOrder() bool {
+	switch s.order {
+	case OrderModTime, OrderSize:
+		return true
+	default:
+		return false
+	}
+}
+
+// sortEntries sorts the children of a directory by name.
+func (s *digger) sortEntries(entries []*dirEntry) {
+	switch s.order {
+	case OrderNatural:
+		sort.SliceStable(entries, func(i, j int) bool {
+			return naturalLess(filepath.Base(entries[i].path), filepath.Base(entries[j].path))
+		})
+	default:
+		sort.Slice(entries, func(i, j int) bool {
+			return entries[i].path < entries[j].path
+		})
+	}
+	if s.reverseOrder && !s.needsStatToOrder() {
+		for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+			entries[i], entries[j] = entries[j], entries[i]
+		}
+	}
+}
+
+// sortNodes sorts the children sorted by sortEntries by the stats.
+func (s *digger) sortNodes(nodes []*node) {
+	var less func(a, b *fileInfo) bool
+	switch s.order {
+	case OrderModTime:
+		less = func(a, b *fileInfo) bool { return a.ModTime().Before(b.ModTime()) }
+	case OrderSize:
+		less = func(a, b *fileInfo) bool { return a.Size() < b.Size() }
+	default:
+		return
+	}
+	if s.reverseOrder {
+		x := less
+		less = func(a, b *fileInfo) bool { return x(b, a) }
+	}
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return less(nodes[i].info, nodes[j].info)
+	})
+}
+
+// naturalLess compares the strings by treating the runs of digits as numbers.
+func naturalLess(a, b string) bool {
+	x, y := a, b
+	for x != "" && y != "" {
+		if isASCIIDigit(x[0]) && isASCIIDigit(y[0]) {
+			var xd, yd string
+			xd, x = splitDigits(x)
+			yd, y = splitDigits(y)
+			xn, yn := strings.TrimLeft(xd, "0"), strings.TrimLeft(yd, "0")
+			if len(xn) != len(yn) {
+				return len(xn) < len(yn)
+			}
+			if xn != yn {
+				return xn < yn
+			}
+			continue
+		}
+		if x[0] != y[0] {
+			return x[0] < y[0]
+		}
+		x, y = x[1:], y[1:]
+	}
+	if len(x) != len(y) {
+		return len(x) < len(y)
+	}
+	// equal as natural, e.g. a01 and a1
+	return a < b
+}
+
+func isASCIIDigit(c byte) bool { return '0' <= c && c <= '9' }
+
+// splitDigits splits s into the leading digits and the rest.
+func splitDigits(s string) (string, string) {
+	i := 0
+	for i < len(s) && isASCIIDigit(s[i]) {
+		i++
+	}
+	return s[:i], s[i:]
+}
@@ -0,0 +1,220 @@
+package dig
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/berquerant/dql/errors"
+)
+
+// WithParallel makes Digger stat files and read directories by n goroutines concurrently.
+// The handler is not called concurrently.
+// No effect if n is less than 2.
+func WithParallel(n int) Option {
+	return func(s *digger) {
+		s.parallel = n
+	}
+}
+
+// WithUnordered makes Digger with WithParallel pass files to the handler as soon as they are stat'ed
+// instead of in the order by WithTraversal and WithOrder, if v is true.
+func WithUnordered(v bool) Option {
+	return func(s *digger) {
+		s.unordered = v
+	}
+}
+
+func newParallelDigger(s *digger) Digger {
+	if h := s.skipHandler; h != nil {
+		var mux sync.Mutex
+		s.skipHandler = func(name string, err error) {
+			mux.Lock()
+			defer mux.Unlock()
+			h(name, err)
+		}
+	}
+	return &parallelDigger{
+		digger: s,
+	}
+}
+
+type parallelDigger struct {
+	*digger
+}
+
+func (s *parallelDigger) Dig(name string, handler FileInfoHandler) error {
+	t, root, err := s.root(name)
+	if err != nil || root == nil {
+		return err
+	}
+	sem := make(chan struct{}, s.parallel)
+	switch {
+	case s.unordered:
+		err = newUnorderedWalk(s.digger, t, handler, sem).run(root)
+	case s.traversal == BreadthFirst:
+		err = s.digBreadthFirst(root, handler, func(n *node) ([]*node, error) {
+			return s.visitChildren(t, n, sem)
+		})
+	default:
+		err = s.dig(t, root, handler, sem)
+	}
+	if err != nil && !errors.Is(err, errDone) {
+		return err
+	}
+	return nil
+}
+
+// dig digs in the same order as digger, but stats the children of a directory concurrently.
+func (s *parallelDigger) dig(t *target, n *node, handler FileInfoHandler, sem chan struct{}) error {
+	switch instr := s.call(n, handler); instr {
+	case InstrCancel:
+		return errDone
+	case InstrSkipDir:
+		return nil
+	case InstrContinue:
+		nodes, err := s.visitChildren(t, n, sem)
+		if err != nil {
+			return err
+		}
+		for _, c := range nodes {
+			if err := s.dig(t, c, handler, sem); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		panic(fmt.Sprintf("dig encountered unknown Instr %s", instr))
+	}
+}
+
+// visitChildren stats the children of the directory concurrently and returns the nodes in order.
+func (s *parallelDigger) visitChildren(t *target, n *node, sem chan struct{}) ([]*node, error) {
+	entries, current, scope, err := s.children(t, n)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		nodes = make([]*node, len(entries))
+		errs  = make([]error, len(entries))
+		wg    sync.WaitGroup
+	)
+	for i, e := range entries {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(i int, e *dirEntry) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			nodes[i], errs[i] = s.visit(t, current, scope, e, n.depth+1)
+		}(i, e)
+	}
+	wg.Wait()
+	result := make([]*node, 0, len(nodes))
+	for i, c := range nodes {
+		if errs[i] != nil {
+			return nil, errs[i]
+		}
+		if c != nil {
+			result = append(result, c)
+		}
+	}
+	s.sortNodes(result)
+	return result, nil
+}
+
+// unorderedWalk digs the directories concurrently.
+type unorderedWalk struct {
+	*digger
+	t       *target
+	handler FileInfoHandler
+	// sem limits the number of goroutines reading files.
+	sem chan struct{}
+	wg  sync.WaitGroup
+	// handlerMux serializes the handler invocations.
+	handlerMux sync.Mutex
+	isDone     int32
+	errMux     sync.Mutex
+	err        error
+}
+
+func newUnorderedWalk(d *digger, t *target, handler FileInfoHandler, sem chan struct{}) *unorderedWalk {
+	return &unorderedWalk{
+		digger:  d,
+		t:       t,
+		handler: handler,
+		sem:     sem,
+	}
+}
+
+func (s *unorderedWalk) run(root *node) error {
+	s.dig(root)
+	s.wg.Wait()
+	return s.err
+}
+
+func (s *unorderedWalk) done() bool { return atomic.LoadInt32(&s.isDone) == 1 }
+
+// fail stops the walk and records the first error.
+func (s *unorderedWalk) fail(err error) {
+	s.errMux.Lock()
+	defer s.errMux.Unlock()
+	if s.err == nil {
+		s.err = err
+	}
+	atomic.StoreInt32(&s.isDone, 1)
+}
+
+// call invokes the handler exclusively.
+// Returns InstrCancel if the walk has been stopped.
+func (s *unorderedWalk) call(n *node) Instr {
+	s.handlerMux.Lock()
+	defer s.handlerMux.Unlock()
+	if s.done() {
+		return InstrCancel
+	}
+	instr := s.digger.call(n, s.handler)
+	if instr == InstrCancel {
+		s.fail(errDone)
+	}
+	return instr
+}
+
+// dig passes n to the handler and digs its children in new goroutines.
+// The caller should not hold sem.
+func (s *unorderedWalk) dig(n *node) {
+	switch instr := s.call(n); instr {
+	case InstrCancel, InstrSkipDir:
+		return
+	case InstrContinue:
+		s.sem <- struct{}{}
+		entries, current, scope, err := s.children(s.t, n)
+		<-s.sem
+		if err != nil {
+			s.fail(err)
+			return
+		}
+		for _, e := range entries {
+			if s.done() {
+				return
+			}
+			s.sem <- struct{}{}
+			s.wg.Add(1)
+			go func(e *dirEntry) {
+				defer s.wg.Done()
+				c, err := s.visit(s.t, current, scope, e, n.depth+1)
+				<-s.sem
+				if err != nil {
+					s.fail(err)
+					return
+				}
+				if c != nil {
+					s.dig(c)
+				}
+			}(e)
+		}
+	default:
+		panic(fmt.Sprintf("dig encountered unknown Instr %s", instr))
+	}
+}
@@ -0,0 +1,113 @@
+package dql
+
+import (
+	"context"
+	"strings"
+
+	"github.com/berquerant/dql/ast"
+	"github.com/berquerant/dql/cc"
+	"github.com/berquerant/dql/data"
+	"github.com/berquerant/dql/dig"
+	"github.com/berquerant/dql/errors"
+	"github.com/berquerant/dql/eval"
+)
+
+var (
+	ErrSyntax = errors.New("syntax error")
+	// ErrSemantic is the error of the query that is well-formed but cannot run,
+	// e.g. unknown columns, mismatched types and misused aggregations.
+	ErrSemantic = eval.ErrSemantic
+)
+
+type (
+	// Row is a result row, the values in the order of Schema.
+	Row []data.Data
+
+	// Column describes a column of the result rows.
+	Column = eval.ColumnSchema
+
+	// Query is a compiled query.
+	// Query can be run multiple times.
+	Query struct {
+		stmt   *ast.Statement
+		runner eval.Runner
+	}
+)
+
+// Compile parses the query and prepares it to run.
+// Returns an error wrapping ErrSyntax if the query is invalid,
+// an error of ErrSemantic reporting all the problems if the query cannot run.
+func Compile(query string, opt ...Option) (*Query, error) {
+	stmt, err := parse(query)
+	if err != nil {
+		return nil, err
+	}
+	if err := eval.Preprocess(stmt); err != nil {
+		return nil, errors.Wrap(err, "compile")
+	}
+
+	c := newConfig()
+	for _, o := range opt {
+		o(c)
+	}
+	if err := eval.Check(stmt, c.functions...); err != nil {
+		return nil, err
+	}
+	// the options of the caller may override lazy stat
+	digOptions := append([]dig.Option{dig.WithLazyStat(!eval.NeedsStat(stmt))}, c.digOptions...)
+	runnerOptions := []eval.RunnerOption{
+		eval.WithMemoryBudget(c.memoryBudget),
+		eval.WithTempDir(c.tempDir),
+		eval.WithWorkers(c.workers),
+	}
+	if len(c.functions) > 0 {
+		runnerOptions = append(runnerOptions, eval.WithFunctions(c.functions...))
+	}
+	return &Query{
+		stmt:   stmt,
+		runner: eval.NewRunner(stmt, dig.New(digOptions...), runnerOptions...),
+	}, nil
+}
+
+func parse(query string) (*ast.Statement, error) {
+	lexer := cc.NewLexer(strings.NewReader(query))
+	status := cc.Parse(lexer)
+	if err := lexer.Err(); err != nil {
+		return nil, errors.Wrap(ErrSyntax, "%v", err)
+	}
+	if status != 0 {
+		return nil, errors.Wrap(ErrSyntax, "parser exit status %d", status)
+	}
+	stmt, ok := lexer.Result().(*ast.Statement)
+	if !ok {
+		return nil, errors.Wrap(ErrSyntax, "no statement")
+	}
+	return stmt, nil
+}
+
+// Schema returns the columns of the result rows.
+func (s *Query) Schema() []Column { return s.runner.Schema() }
+
+// String returns the query after preprocessing.
+func (s *Query) String() string { return s.stmt.String() }
+
+// Run digs the roots and returns the result rows.
+// Stops at the first error.
+func (s *Query) Run(ctx context.Context, roots ...string) ([]Row, error) {
+	cur := s.runner.Open(ctx, roots...)
+	defer cur.Close()
+
+	rows := []Row{}
+	for cur.Next() {
+		r := cur.Row()
+		row := make(Row, r.Len())
+		for i := range row {
+			row[i] = r.Get(i)
+		}
+		rows = append(rows, row)
+	}
+	if err := cur.Err(); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
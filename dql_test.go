@@ -0,0 +1,184 @@
+package dql_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/berquerant/dql"
+	"github.com/berquerant/dql/data"
+	"github.com/berquerant/dql/dig"
+	"github.com/berquerant/dql/errors"
+	"github.com/berquerant/dql/function"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuery(t *testing.T) {
+	root := t.TempDir()
+	for _, f := range []string{"a.go", "b.txt", "d/c.go", "d/e/f.go"} {
+		p := filepath.Join(root, f)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(f), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var (
+		upper = function.NewFunction("upper", func(args ...data.Data) (data.Data, error) {
+			if len(args) != 1 || args[0].Type() != data.TypeString {
+				return nil, function.ErrInvalidArgument
+			}
+			return data.FromString(strings.ToUpper(args[0].String())), nil
+		})
+		// values returns the rows as strings
+		values = func(rows []dql.Row) []string {
+			r := make([]string, len(rows))
+			for i, row := range rows {
+				xs := make([]string, len(row))
+				for j, x := range row {
+					xs[j] = fmt.Sprint(x.Value())
+				}
+				r[i] = strings.Join(xs, " ")
+			}
+			return r
+		}
+	)
+
+	for _, tc := range []*struct {
+		title   string
+		query   string
+		opt     []dql.Option
+		schema  []string
+		want    []string
+		err     error
+		runFail bool
+	}{
+		{
+			title: "syntax error",
+			query: "select name where;",
+			err:   dql.ErrSyntax,
+		},
+		{
+			title: "lex error",
+			query: `select "name;`,
+			err:   dql.ErrSyntax,
+		},
+		{
+			title:  "select",
+			query:  `select rel_name, size where not is_dir and rel_name like "\.go$";`,
+			schema: []string{"rel_name", "size"},
+			want:   []string{"a.go 4", "d/c.go 6", "d/e/f.go 8"},
+		},
+		{
+			title:  "alias and aggregation",
+			query:  "select is_dir as d, count(name) as n group by is_dir order by d;",
+			schema: []string{"d", "n"},
+			want:   []string{"false 4", "true 3"},
+		},
+		{
+			title:  "select all",
+			query:  "select all limit 1;",
+			schema: []string{"name", "size", "mode", "mod_time", "is_dir"},
+			want:   nil,
+		},
+		{
+			title:  "dig options",
+			query:  "select rel_name where not is_dir;",
+			opt:    []dql.Option{dql.WithDigOptions(dig.WithMaxDepth(1))},
+			schema: []string{"rel_name"},
+			want:   []string{"a.go", "b.txt"},
+		},
+		{
+			title:  "function",
+			query:  `select upper(rel_name) where rel_name = "b.txt";`,
+			opt:    []dql.Option{dql.WithFunctions(upper), dql.WithWorkers(4)},
+			schema: []string{"upper(rel_name)"},
+			want:   []string{"B.TXT"},
+		},
+		{
+			title:  "int literal with base prefix",
+			query:  `select size & 0b11, 0x1ff where rel_name = "b.txt";`,
+			schema: []string{"size & 0b11", "0x1ff"},
+			want:   []string{"1 511"},
+		},
+		{
+			title: "unknown function",
+			query: `select upper(rel_name);`,
+			err:   dql.ErrSemantic,
+		},
+		{
+			title: "semantic error",
+			query: `select name, count(name) where size > "1";`,
+			err:   dql.ErrSemantic,
+		},
+		{
+			title:   "function error",
+			query:   `select upper(size);`,
+			opt:     []dql.Option{dql.WithFunctions(upper)},
+			schema:  []string{"upper(size)"},
+			runFail: true,
+		},
+		{
+			title:  "memory budget",
+			query:  "select rel_name where not is_dir order by size desc;",
+			opt:    []dql.Option{dql.WithMemoryBudget(1), dql.WithTempDir(t.TempDir())},
+			schema: []string{"rel_name"},
+			want:   []string{"d/e/f.go", "d/c.go", "b.txt", "a.go"},
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			q, err := dql.Compile(tc.query, tc.opt...)
+			if tc.err != nil {
+				assert.True(t, errors.Is(err, tc.err), "%v", err)
+				return
+			}
+			if !assert.Nil(t, err) {
+				return
+			}
+			schema := make([]string, len(q.Schema()))
+			for i, c := range q.Schema() {
+				schema[i] = c.Name
+			}
+			assert.Equal(t, tc.schema, schema)
+
+			rows, err := q.Run(context.TODO(), root)
+			if tc.runFail {
+				assert.NotNil(t, err)
+				return
+			}
+			if !assert.Nil(t, err) {
+				return
+			}
+			if tc.want == nil {
+				// the values depend on the environment
+				assert.Equal(t, 1, len(rows))
+				assert.Equal(t, len(tc.schema), len(rows[0]))
+				return
+			}
+			assert.Equal(t, tc.want, values(rows))
+		})
+	}
+
+	t.Run("run twice", func(t *testing.T) {
+		q, err := dql.Compile("select count(name);")
+		if !assert.Nil(t, err) {
+			return
+		}
+		assert.Equal(t, []dql.Column{{
+			Name:  "count(name)",
+			Types: []data.Type{data.TypeInt},
+			Expr:  "count(name)",
+		}}, q.Schema())
+		for i := 0; i < 2; i++ {
+			rows, err := q.Run(context.TODO(), root)
+			assert.Nil(t, err)
+			assert.Equal(t, []string{"7"}, values(rows))
+		}
+	})
+}
@@ -0,0 +1,457 @@
+package eval
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/berquerant/dql/ast"
+	"github.com/berquerant/dql/env"
+	"github.com/berquerant/dql/errors"
+	"github.com/berquerant/dql/function"
+)
+
+var (
+	ErrSemantic = errors.New("semantic error")
+)
+
+// Problem is a semantic error of a statement.
+type Problem struct {
+	// Section is the section of the statement having the problem, e.g. where.
+	Section string
+	// Expr is the expr having the problem.
+	Expr string
+	Msg  string
+}
+
+func (s *Problem) String() string {
+	if s.Expr == "" {
+		return fmt.Sprintf("%s: %s", s.Section, s.Msg)
+	}
+	return fmt.Sprintf("%s %s: %s", s.Section, s.Expr, s.Msg)
+}
+
+// Problems are the semantic errors of a statement, an error of ErrSemantic.
+type Problems []*Problem
+
+func (s Problems) Error() string {
+	xs := make([]string, len(s))
+	for i, p := range s {
+		xs[i] = p.String()
+	}
+	return fmt.Sprintf("%s: %s", ErrSemantic, strings.Join(xs, "; "))
+}
+
+func (Problems) Is(target error) bool { return target == ErrSemantic }
+
+// Check analyzes the preprocessed statement without reading any files.
+// It resolves the idents and the aliases, checks the types of the operators and the functions,
+// and the rules of the aggregations.
+// Returns Problems if any.
+// functions are the functions added by WithFunctions, their types are unknown.
+func Check(stmt *ast.Statement, functions ...function.Function) error {
+	c := newChecker(stmt, functions)
+	c.check()
+	if len(c.problems) > 0 {
+		return c.problems
+	}
+	return nil
+}
+
+type checker struct {
+	stmt *ast.Statement
+	// table has the aliases
+	table     env.Map
+	functions map[string]bool
+	inference *typeInference
+	// groupKey is the column of group by, empty if no group by
+	groupKey string
+	// section is the section being checked
+	section  string
+	problems Problems
+}
+
+func newChecker(stmt *ast.Statement, functions []function.Function) *checker {
+	var (
+		table = env.New()
+		names = make([]string, len(functions))
+		fs    = make(map[string]bool, len(functions))
+	)
+	for _, t := range stmt.SelectSection.Terms.Terms {
+		if t.As != nil {
+			table.Set(t.As.Value, env.FromExpr(t.Target.Expr))
+		}
+	}
+	for i, f := range functions {
+		names[i] = f.Name()
+		fs[f.Name()] = true
+	}
+	c := &checker{
+		stmt:      stmt,
+		table:     table,
+		functions: fs,
+		inference: newTypeInference(table, names),
+	}
+	c.inference.report = func(expr ast.Expr, format string, v ...interface{}) {
+		c.errorf(expr, format, v...)
+	}
+	return c
+}
+
+func (s *checker) errorf(expr ast.Expr, format string, v ...interface{}) {
+	p := &Problem{
+		Section: s.section,
+		Msg:     fmt.Sprintf(format, v...),
+	}
+	if expr != nil {
+		p.Expr = expr.String()
+	}
+	s.problems = append(s.problems, p)
+}
+
+func (s *checker) check() {
+	s.checkGroupBy()
+	s.checkSelect()
+	if x := s.stmt.PruneSection; x != nil {
+		s.section = "prune where"
+		s.checkCondition(x.Condition.Expr)
+	}
+	if x := s.stmt.WhereSection; x != nil {
+		s.section = "where"
+		s.checkCondition(x.Condition.Expr)
+	}
+	s.checkHaving()
+	s.checkOrderBy()
+	s.checkLimit()
+}
+
+func (s *checker) checkGroupBy() {
+	x := s.stmt.GroupBySection
+	if x == nil {
+		return
+	}
+	s.section = "group by"
+	if len(x.Terms.Terms) > 1 {
+		s.errorf(nil, "only one column is supported")
+	}
+	expr := x.Terms.Terms[0].Expr
+	name, ok := identName(expr)
+	if !ok {
+		s.errorf(expr, "want a column")
+		return
+	}
+	if _, ok := columnSlots[name]; !ok {
+		if _, ok := s.table.Get(name); ok {
+			s.errorf(expr, "want a column but got an alias")
+		} else {
+			s.errorf(expr, "unknown column")
+		}
+		return
+	}
+	s.groupKey = name
+}
+
+func (s *checker) checkSelect() {
+	s.section = "select"
+	if x := s.stmt.SelectSection.Option; x != nil && x.IsDistinct {
+		s.errorf(nil, "distinct is not supported")
+	}
+	var (
+		terms = s.stmt.SelectSection.Terms.Terms
+		refs  = make([]*exprRefs, len(terms))
+		// isAggregation is true if select aggregates the rows
+		isAggregation = s.groupKey != ""
+	)
+	for i, t := range terms {
+		refs[i] = s.refs(t.Target.Expr)
+		s.inference.infer(t.Target.Expr)
+		isAggregation = isAggregation || refs[i].aggregation
+	}
+	if !isAggregation || s.invalidGroupBy() {
+		return
+	}
+	for i, t := range terms {
+		for _, c := range refs[i].columns {
+			if c == s.groupKey {
+				continue
+			}
+			if s.groupKey == "" {
+				s.errorf(t.Target.Expr, "column %s cannot be selected with aggregations without group by", c)
+			} else {
+				s.errorf(t.Target.Expr, "column %s is neither aggregated nor the group by column", c)
+			}
+		}
+	}
+}
+
+// checkCondition checks the condition evaluated on the rows before grouping.
+func (s *checker) checkCondition(expr ast.Expr) {
+	if s.refs(expr).aggregation {
+		s.errorf(expr, "aggregation is not available")
+	}
+	s.checkBool(expr)
+}
+
+func (s *checker) checkBool(expr ast.Expr) {
+	if x := s.inference.infer(expr); x.types.IsDisjoint(function.TypeBool) {
+		s.errorf(expr, "want bool but got %s", x.types)
+	}
+}
+
+func (s *checker) checkHaving() {
+	x := s.stmt.HavingSection
+	if x == nil {
+		return
+	}
+	s.section = "having"
+	expr := x.Condition.Expr
+	if s.stmt.GroupBySection == nil {
+		s.errorf(nil, "want group by")
+		s.refs(expr)
+	} else {
+		s.checkGrouped(expr)
+	}
+	s.checkBool(expr)
+}
+
+func (s *checker) checkOrderBy() {
+	x := s.stmt.OrderBySection
+	if x == nil {
+		return
+	}
+	s.section = "order by"
+	if len(x.Terms.Terms) > 1 {
+		s.errorf(nil, "only one expr is supported")
+	}
+	expr := x.Terms.Terms[0].Expr
+	if s.stmt.GroupBySection != nil {
+		s.checkGrouped(expr)
+	} else if s.refs(expr).aggregation {
+		s.errorf(expr, "aggregation is not available without group by")
+	}
+	s.inference.infer(expr)
+}
+
+// checkGrouped checks the expr evaluated on the grouped rows.
+func (s *checker) checkGrouped(expr ast.Expr) {
+	refs := s.refs(expr)
+	if s.invalidGroupBy() {
+		return
+	}
+	for _, c := range refs.columns {
+		if c != s.groupKey {
+			s.errorf(expr, "column %s is neither aggregated nor the group by column", c)
+		}
+	}
+}
+
+// invalidGroupBy returns true if group by has no valid column,
+// then the columns of the grouped rows are not checked.
+func (s *checker) invalidGroupBy() bool {
+	return s.stmt.GroupBySection != nil && s.groupKey == ""
+}
+
+func (s *checker) checkLimit() {
+	x := s.stmt.LimitSection
+	if x == nil {
+		return
+	}
+	s.section = "limit"
+	if x.Limit.Value < 1 {
+		s.errorf(nil, "want positive limit but got %d", x.Limit.Value)
+	}
+	if x.Offset != nil && x.Offset.Value < 0 {
+		s.errorf(nil, "want non-negative offset but got %d", x.Offset.Value)
+	}
+}
+
+// exprRefs are the references of an expr.
+type exprRefs struct {
+	// columns are the columns referred not in aggregations
+	columns []string
+	// aggregation is true if the expr contains aggregations
+	aggregation bool
+}
+
+func (s *exprRefs) addColumn(name string) {
+	for _, c := range s.columns {
+		if c == name {
+			return
+		}
+	}
+	s.columns = append(s.columns, name)
+}
+
+// refs resolves the idents and the functions of the expr and returns the references.
+func (s *checker) refs(expr ast.Expr) *exprRefs {
+	w := &refsWalker{
+		checker: s,
+		refs:    &exprRefs{},
+		aliases: map[string]bool{},
+	}
+	w.walk(expr)
+	return w.refs
+}
+
+type refsWalker struct {
+	*checker
+	refs *exprRefs
+	// aggregation is the references of the arg of the aggregation being walked
+	aggregation *exprRefs
+	// aliases are the aliases being walked, to detect cyclic references
+	aliases map[string]bool
+	// quiet is true while walking the aliases.
+	// The errors in the aliases are reported where they are selected.
+	quiet bool
+}
+
+func (s *refsWalker) errorf(expr ast.Expr, format string, v ...interface{}) {
+	if !s.quiet {
+		s.checker.errorf(expr, format, v...)
+	}
+}
+
+func (s *refsWalker) walk(expr ast.Expr) {
+	switch expr := expr.(type) {
+	case *ast.Ident:
+		s.ident(expr)
+	case *ast.FunctionCall:
+		s.functionCall(expr)
+	default:
+		for _, x := range subExprs(expr) {
+			s.walk(x)
+		}
+	}
+}
+
+func (s *refsWalker) ident(expr *ast.Ident) {
+	name := expr.Value
+	if _, ok := columnSlots[name]; ok {
+		if s.aggregation != nil {
+			s.aggregation.addColumn(name)
+		} else {
+			s.refs.addColumn(name)
+		}
+		return
+	}
+	v, ok := s.table.Get(name)
+	if !ok {
+		s.errorf(expr, "unknown column")
+		return
+	}
+	if s.aliases[name] {
+		// reported even in the aliases, the evaluators cannot resolve it
+		s.checker.errorf(expr, "cyclic alias")
+		return
+	}
+	s.aliases[name] = true
+	quiet := s.quiet
+	s.quiet = true
+	s.walk(v.Expr())
+	s.quiet = quiet
+	delete(s.aliases, name)
+}
+
+func (s *refsWalker) functionCall(expr *ast.FunctionCall) {
+	var args []ast.Expr
+	if expr.Arguments != nil {
+		args = expr.Arguments.Exprs
+	}
+	name := expr.FunctionName.Value
+	if !s.functions[name] {
+		sig, ok := function.LookupSignature(name)
+		if !ok {
+			s.errorf(expr, "unknown function %s", name)
+		} else if sig.Aggregation {
+			s.aggregationCall(expr, args)
+			return
+		}
+	}
+	for _, a := range args {
+		s.walk(a)
+	}
+}
+
+func (s *refsWalker) aggregationCall(expr *ast.FunctionCall, args []ast.Expr) {
+	s.refs.aggregation = true
+	if s.aggregation != nil {
+		// reported even in the aliases, since it depends on where the alias is used
+		s.checker.errorf(expr, "aggregations cannot be nested")
+		for _, a := range args {
+			s.walk(a)
+		}
+		return
+	}
+	s.aggregation = &exprRefs{}
+	for _, a := range args {
+		s.walk(a)
+	}
+	r := s.aggregation
+	s.aggregation = nil
+	if len(args) != 1 {
+		// reported by the type inference
+		return
+	}
+	switch len(r.columns) {
+	case 0:
+		s.errorf(expr, "want a column to aggregate")
+	case 1:
+		if c := r.columns[0]; c == s.groupKey {
+			s.checker.errorf(expr, "cannot aggregate the group by column %s", c)
+		}
+	default:
+		s.errorf(expr, "cannot aggregate multiple columns %s", strings.Join(r.columns, ", "))
+	}
+}
+
+// identName returns the name if expr is just an ident.
+func identName(expr ast.Expr) (string, bool) {
+	for {
+		switch x := expr.(type) {
+		case *ast.BoolPrimaryPredicate:
+			expr = x.Pred
+		case *ast.PredicateBitExpr:
+			expr = x.Expr
+		case *ast.BitExprSimpleExpr:
+			expr = x.Expr
+		case *ast.Ident:
+			return x.Value, true
+		default:
+			return "", false
+		}
+	}
+}
+
+// subExprs returns the direct children of the expr.
+func subExprs(expr ast.Expr) []ast.Expr {
+	switch expr := expr.(type) {
+	case ast.BinaryOp:
+		return []ast.Expr{expr.LeftArg(), expr.RightArg()}
+	case ast.UnaryOp:
+		return []ast.Expr{expr.Arg()}
+	case *ast.BoolPrimaryPredicate:
+		return []ast.Expr{expr.Pred}
+	case *ast.PredicateIn:
+		r := []ast.Expr{expr.Target}
+		if expr.List != nil {
+			r = append(r, expr.List.Exprs...)
+		}
+		return r
+	case *ast.PredicateBetween:
+		return []ast.Expr{expr.Target, expr.Left, expr.Right}
+	case *ast.PredicateLike:
+		return []ast.Expr{expr.Target, expr.Pattern}
+	case *ast.PredicateBitExpr:
+		return []ast.Expr{expr.Expr}
+	case *ast.BitExprSimpleExpr:
+		return []ast.Expr{expr.Expr}
+	case *ast.SimpleExprExpr:
+		return []ast.Expr{expr.Expr}
+	case *ast.FunctionCall:
+		if expr.Arguments == nil {
+			return nil
+		}
+		return expr.Arguments.Exprs
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,244 @@
+package eval_test
+
+import (
+	"testing"
+
+	"github.com/berquerant/dql/data"
+	"github.com/berquerant/dql/errors"
+	"github.com/berquerant/dql/eval"
+	"github.com/berquerant/dql/function"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheck(t *testing.T) {
+	upper := function.NewFunction("upper", func(args ...data.Data) (data.Data, error) {
+		return args[0], nil
+	})
+
+	for _, tc := range []*struct {
+		title     string
+		query     string
+		functions []function.Function
+		// want are the problems
+		want []string
+	}{
+		{
+			title: "select",
+			query: `select name, size where not is_dir and name like "\.go$";`,
+		},
+		{
+			title: "select all",
+			query: "select all;",
+		},
+		{
+			title: "select stat",
+			query: "select stat, is_dir where not is_dir;",
+		},
+		{
+			title: "prune",
+			query: `select name prune where base(name) in (".git", "node_modules");`,
+		},
+		{
+			title: "alias",
+			query: `select name as n, n as m, size as s where m = "a" order by s desc limit 3 offset 1;`,
+		},
+		{
+			title: "cast alias",
+			query: `select cast(size, "string") as s where s like "1";`,
+		},
+		{
+			title: "aggregation",
+			query: "select max(size) + 1, count(name), 1;",
+		},
+		{
+			title: "group by",
+			query: "select is_dir, count(name) as c group by is_dir having c > 1 order by c;",
+		},
+		{
+			title: "having aggregation",
+			query: "select mode, count(name) group by mode having count(name) > 5;",
+		},
+		{
+			title: "default argument",
+			query: `select relpath(name), grep("a", name), path_join(root, name, "x");`,
+		},
+		{
+			title:     "additional function",
+			query:     "select upper(name), upper(size);",
+			functions: []function.Function{upper},
+		},
+		{
+			title: "unknown column",
+			query: "select nosuch;",
+			want:  []string{"select nosuch: unknown column"},
+		},
+		{
+			title: "unknown function",
+			query: "select upper(name);",
+			want:  []string{"select upper(name): unknown function upper"},
+		},
+		{
+			title: "cyclic alias",
+			query: "select x as y, y as x;",
+			want: []string{
+				"select x: cyclic alias",
+				"select y: cyclic alias",
+			},
+		},
+		{
+			title: "operator type",
+			query: `select size + "a";`,
+			want:  []string{`select size + "a": "a" want number but got string`},
+		},
+		{
+			title: "comparison type",
+			query: "select name where size in (1, 2.0);",
+			want:  []string{"where size in (1, 2): cannot compare int and float"},
+		},
+		{
+			title: "function arity",
+			query: "select len(name, name);",
+			want:  []string{"select len(name, name): len want 1 args but got 2"},
+		},
+		{
+			title: "function argument type",
+			query: "select len(size);",
+			want:  []string{"select len(size): size want string but got int"},
+		},
+		{
+			title: "alias type",
+			query: `select name as n, n + 1;`,
+			want:  []string{"select n + 1: n want number but got string"},
+		},
+		{
+			title: "condition type",
+			query: "select name prune where name where size;",
+			want: []string{
+				"prune where name: want bool but got string",
+				"where size: want bool but got int",
+			},
+		},
+		{
+			title: "all problems",
+			query: `select name, size + "a", nosuch, foo(1) where size;`,
+			want: []string{
+				`select size + "a": "a" want number but got string`,
+				"select nosuch: unknown column",
+				"select foo(1): unknown function foo",
+				"where size: want bool but got int",
+			},
+		},
+		{
+			title: "raw column with aggregation",
+			query: "select name, count(name);",
+			want:  []string{"select name: column name cannot be selected with aggregations without group by"},
+		},
+		{
+			title: "aggregation in where",
+			query: "select name where count(name) > 1;",
+			want:  []string{"where count(name) > 1: aggregation is not available"},
+		},
+		{
+			title: "aggregation alias in where",
+			query: "select count(name) as c where c > 1;",
+			want:  []string{"where c > 1: aggregation is not available"},
+		},
+		{
+			title: "nested aggregation",
+			query: "select sum(count(size));",
+			want:  []string{"select count(size): aggregations cannot be nested"},
+		},
+		{
+			title: "aggregate multiple columns",
+			query: "select sum(size + depth_from_root);",
+			want:  []string{"select sum(size + depth_from_root): cannot aggregate multiple columns size, depth_from_root"},
+		},
+		{
+			title: "aggregate no columns",
+			query: "select count(1);",
+			want:  []string{"select count(1): want a column to aggregate"},
+		},
+		{
+			title: "aggregate group by column",
+			query: "select is_dir, count(is_dir) group by is_dir;",
+			want:  []string{"select count(is_dir): cannot aggregate the group by column is_dir"},
+		},
+		{
+			title: "select without group by column",
+			query: "select count(name), max(size) * 2 group by is_dir;",
+		},
+		{
+			title: "raw column with group by",
+			query: "select is_dir, name group by is_dir;",
+			want:  []string{"select name: column name is neither aggregated nor the group by column"},
+		},
+		{
+			title: "group by alias",
+			query: "select is_dir as d, count(name) group by d;",
+			want:  []string{"group by d: want a column but got an alias"},
+		},
+		{
+			title: "group by expr",
+			query: "select count(name) group by size + 1;",
+			want:  []string{"group by size + 1: want a column"},
+		},
+		{
+			title: "group by multiple columns",
+			query: "select is_dir, count(name) group by is_dir, mode;",
+			want:  []string{"group by: only one column is supported"},
+		},
+		{
+			title: "having without group by",
+			query: "select name having is_dir;",
+			want:  []string{"having: want group by"},
+		},
+		{
+			title: "having raw column",
+			query: "select is_dir, count(name) group by is_dir having size > 1;",
+			want:  []string{"having size > 1: column size is neither aggregated nor the group by column"},
+		},
+		{
+			title: "order by aggregation without group by",
+			query: "select name order by count(name);",
+			want:  []string{"order by count(name): aggregation is not available without group by"},
+		},
+		{
+			title: "order by raw column with group by",
+			query: "select is_dir, count(name) group by is_dir order by size;",
+			want:  []string{"order by size: column size is neither aggregated nor the group by column"},
+		},
+		{
+			title: "order by multiple exprs",
+			query: "select name order by size, name;",
+			want:  []string{"order by: only one expr is supported"},
+		},
+		{
+			title: "limit",
+			query: "select name limit 0;",
+			want:  []string{"limit: want positive limit but got 0"},
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			stmt := parseStatement(t, tc.query)
+			if !assert.Nil(t, eval.Preprocess(stmt)) {
+				return
+			}
+			err := eval.Check(stmt, tc.functions...)
+			if len(tc.want) == 0 {
+				assert.Nil(t, err)
+				return
+			}
+			assert.True(t, errors.Is(err, eval.ErrSemantic), "%v", err)
+			problems, ok := err.(eval.Problems)
+			if !assert.True(t, ok) {
+				return
+			}
+			got := make([]string, len(problems))
+			for i, p := range problems {
+				got[i] = p.String()
+			}
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package eval
+
+import (
+	"github.com/berquerant/dql/ast"
+	"github.com/berquerant/dql/data"
+)
+
+// column is a column of the rows.
+type column struct {
+	name string
+	get  func(Info) data.Data
+	typ  data.Type
+	// stat is true if the column requires the stat of the file
+	stat bool
+	// nullable is true if the value may be unavailable, then it is the zero value
+	nullable bool
+}
+
+// columns are all columns of the rows, the index is the slot of the column.
+var columns = []*column{
+	{name: "name", typ: data.TypeString, get: func(v Info) data.Data { return data.FromString(v.Name()) }},
+	{name: "size", typ: data.TypeInt, get: func(v Info) data.Data { return data.FromInt(v.Size()) }, stat: true},
+	{name: "mode", typ: data.TypeString, get: func(v Info) data.Data { return data.FromString(v.Mode()) }, stat: true},
+	{name: "mod_time", typ: data.TypeInt, get: func(v Info) data.Data { return data.FromInt(v.ModTime()) }, stat: true},
+	{name: "is_dir", typ: data.TypeBool, get: func(v Info) data.Data { return data.FromBool(v.IsDir()) }},
+	{name: "root", typ: data.TypeString, get: func(v Info) data.Data { return data.FromString(v.Root()) }},
+	{name: "rel_name", typ: data.TypeString, get: func(v Info) data.Data { return data.FromString(v.RelName()) }},
+	{name: "depth_from_root", typ: data.TypeInt, get: func(v Info) data.Data { return data.FromInt(v.DepthFromRoot()) }},
+	{name: "inode", typ: data.TypeInt, get: func(v Info) data.Data { return data.FromInt(v.Inode()) }, stat: true, nullable: true},
+	{name: "dev", typ: data.TypeInt, get: func(v Info) data.Data { return data.FromInt(v.Dev()) }, stat: true, nullable: true},
+	{name: "nlink", typ: data.TypeInt, get: func(v Info) data.Data { return data.FromInt(v.Nlink()) }, stat: true, nullable: true},
+	{name: "uid", typ: data.TypeInt, get: func(v Info) data.Data { return data.FromInt(v.UID()) }, stat: true, nullable: true},
+	{name: "gid", typ: data.TypeInt, get: func(v Info) data.Data { return data.FromInt(v.GID()) }, stat: true, nullable: true},
+	{name: "owner", typ: data.TypeString, get: func(v Info) data.Data { return data.FromString(v.Owner()) }, stat: true, nullable: true},
+	{name: "group_name", typ: data.TypeString, get: func(v Info) data.Data { return data.FromString(v.Group()) }, stat: true, nullable: true},
+	{name: "atime", typ: data.TypeInt, get: func(v Info) data.Data { return data.FromInt(v.Atime()) }, stat: true, nullable: true},
+	{name: "ctime", typ: data.TypeInt, get: func(v Info) data.Data { return data.FromInt(v.Ctime()) }, stat: true, nullable: true},
+	{name: "blocks", typ: data.TypeInt, get: func(v Info) data.Data { return data.FromInt(v.Blocks()) }, stat: true, nullable: true},
+	{name: "disk_usage", typ: data.TypeInt, get: func(v Info) data.Data { return data.FromInt(v.DiskUsage()) }, stat: true, nullable: true},
+	{name: "is_symlink", typ: data.TypeBool, get: func(v Info) data.Data { return data.FromBool(v.IsSymlink()) }},
+	{name: "link_target", typ: data.TypeString, get: func(v Info) data.Data { return data.FromString(v.LinkTarget()) }, nullable: true},
+	{name: "is_broken_link", typ: data.TypeBool, get: func(v Info) data.Data { return data.FromBool(v.IsBrokenLink()) }},
+	{name: "type", typ: data.TypeString, get: func(v Info) data.Data { return data.FromString(v.Type()) }},
+	{name: "perm", typ: data.TypeString, get: func(v Info) data.Data { return data.FromString(v.Perm()) }, stat: true},
+	{name: "mode_bits", typ: data.TypeInt, get: func(v Info) data.Data { return data.FromInt(v.ModeBits()) }, stat: true},
+	{name: "is_ignored", typ: data.TypeBool, get: func(v Info) data.Data { return data.FromBool(v.IsIgnored()) }},
+	{name: "is_hidden", typ: data.TypeBool, get: func(v Info) data.Data { return data.FromBool(v.IsHidden()) }},
+}
+
+var columnSlots = func() map[string]int {
+	d := make(map[string]int, len(columns))
+	for i, c := range columns {
+		d[c.name] = i
+	}
+	return d
+}()
+
+// columnRegistry resolves the names of columns to the slots, implements calc.Columns.
+type columnRegistry struct{}
+
+func (columnRegistry) Slot(name string) (int, bool) {
+	i, ok := columnSlots[name]
+	return i, ok
+}
+
+// infoToMap returns the columns of the info.
+func infoToMap(v Info) map[string]data.Data {
+	d := make(map[string]data.Data, len(columns))
+	for i, c := range columns {
+		d[c.name] = columnData(v, i)
+	}
+	return d
+}
+
+// columnData returns the value of the column of the info.
+// The value is memoized if the info is read by NewInfo.
+func columnData(v Info, slot int) data.Data {
+	if x, ok := v.(*info); ok {
+		return x.column(slot)
+	}
+	return columns[slot].get(v)
+}
+
+// NeedsStat returns true if the statement refers to the columns that require the stats of the files.
+// The other columns are derived from the paths and the types of the directory entries.
+func NeedsStat(stmt *ast.Statement) bool {
+	var (
+		needs   bool
+		visitor = ast.NewBaseVisitor(func(x ast.Expr) bool {
+			if ident, ok := x.(*ast.Ident); ok && identNeedsStat(ident.Value) {
+				needs = true
+				return false
+			}
+			return true
+		})
+		visit = func(expr ast.Expr) {
+			if expr != nil && !needs {
+				visitor.Init()
+				expr.Accept(visitor)
+			}
+		}
+	)
+	for _, t := range stmt.SelectSection.Terms.Terms {
+		visit(t.Target.Expr)
+	}
+	if x := stmt.PruneSection; x != nil {
+		visit(x.Condition.Expr)
+	}
+	if x := stmt.WhereSection; x != nil {
+		visit(x.Condition.Expr)
+	}
+	if x := stmt.GroupBySection; x != nil {
+		for _, t := range x.Terms.Terms {
+			visit(t.Expr)
+		}
+	}
+	if x := stmt.HavingSection; x != nil {
+		visit(x.Condition.Expr)
+	}
+	if x := stmt.OrderBySection; x != nil {
+		for _, t := range x.Terms.Terms {
+			visit(t.Expr)
+		}
+	}
+	return needs
+}
+
+func identNeedsStat(name string) bool {
+	switch name {
+	case AllSelectSymbol, StatSelectSymbol:
+		return true
+	}
+	slot, ok := columnSlots[name]
+	return ok && columns[slot].stat
+}
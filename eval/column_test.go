@@ -0,0 +1,82 @@
+package eval_test
+
+import (
+	"io/fs"
+	"strings"
+	"testing"
+
+	"github.com/berquerant/dql/ast"
+	"github.com/berquerant/dql/cc"
+	"github.com/berquerant/dql/eval"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNeedsStat(t *testing.T) {
+	for _, tc := range []*struct {
+		query string
+		want  bool
+	}{
+		{query: "select name;"},
+		{query: "select name, is_dir, type, root, rel_name, depth_from_root, is_hidden, is_ignored;"},
+		{query: "select is_symlink, link_target, is_broken_link;"},
+		{query: `select name where depth(name) < 3 and type = "dir" order by name;`},
+		{query: "select is_dir, count(name) group by is_dir having count(name) > 1;"},
+		{query: "select relpath(name) as r order by r;"},
+		{query: "select size;", want: true},
+		{query: "select name, owner;", want: true},
+		{query: "select name where mod_time > 0;", want: true},
+		{query: "select name prune where size > 0;", want: true},
+		{query: "select name order by perm;", want: true},
+		{query: "select is_dir, sum(blocks) group by is_dir;", want: true},
+		{query: "select is_dir, count(name) group by is_dir having sum(size) > 0;", want: true},
+		{query: "select name, size as s order by s;", want: true},
+		{query: "select all;", want: true},
+		{query: "select name, stat;", want: true},
+	} {
+		tc := tc
+		t.Run(tc.query, func(t *testing.T) {
+			lexer := cc.NewLexer(strings.NewReader(tc.query))
+			if status := cc.Parse(lexer); status != 0 {
+				t.Fatalf("failed to parse %s", tc.query)
+			}
+			assert.Equal(t, tc.want, eval.NeedsStat(lexer.Result().(*ast.Statement)))
+		})
+	}
+}
+
+// mockCountingFileInfo counts the calls to read the stat.
+type mockCountingFileInfo struct {
+	mockFileInfo
+	count int
+}
+
+func (s *mockCountingFileInfo) Size() int64 {
+	s.count++
+	return s.mockFileInfo.Size()
+}
+
+func (s *mockCountingFileInfo) Mode() fs.FileMode {
+	s.count++
+	return s.mockFileInfo.Mode()
+}
+
+func TestInfoMemoized(t *testing.T) {
+	v := &mockCountingFileInfo{
+		mockFileInfo: mockFileInfo{
+			name:    "/r/a",
+			size:    10,
+			mode:    0644,
+			relName: "a",
+		},
+	}
+	info := eval.NewInfo(v)
+	assert.Equal(t, 0, v.count, "no stats are read on creation")
+	for i := 0; i < 3; i++ {
+		d := info.ToMap()
+		assert.Equal(t, 10, d["size"].Int())
+		assert.Equal(t, "-rw-r--r--", d["mode"].String())
+		assert.Equal(t, "0644", d["perm"].String())
+	}
+	// size, mode, perm and mode_bits
+	assert.Equal(t, 4, v.count, "each column is read once")
+}
@@ -0,0 +1,92 @@
+package eval
+
+import "context"
+
+// Cursor reads the result rows of Runner one by one.
+//
+//	cur := runner.Open(ctx, names...)
+//	defer cur.Close()
+//	for cur.Next() {
+//		row := cur.Row()
+//	}
+//	if err := cur.Err(); err != nil {
+//		// ...
+//	}
+type Cursor interface {
+	// Next advances to the next row.
+	// Returns false at the end of the rows, on error or after Close.
+	Next() bool
+	// Row returns the current row.
+	Row() SRow
+	// Err returns the error that stopped Next.
+	Err() error
+	// Close stops the runner and waits until all the stages finish.
+	// Close can be called multiple times.
+	Close() error
+}
+
+type cursor struct {
+	resultC <-chan SRow
+	cancel  context.CancelFunc
+	row     SRow
+	err     error
+	closed  bool
+}
+
+// NewCursor returns a new Cursor that reads the rows from resultC.
+// cancel should stop the stages writing resultC.
+func NewCursor(resultC <-chan SRow, cancel context.CancelFunc) Cursor {
+	return &cursor{
+		resultC: resultC,
+		cancel:  cancel,
+	}
+}
+
+func (s *cursor) Next() bool {
+	if s.closed {
+		return false
+	}
+	r, ok := <-s.resultC
+	if !ok {
+		s.row = nil
+		s.Close()
+		return false
+	}
+	if err := r.Err(); err != nil {
+		s.row = nil
+		s.err = err
+		s.Close()
+		return false
+	}
+	s.row = r
+	return true
+}
+
+func (s *cursor) Row() SRow  { return s.row }
+func (s *cursor) Err() error { return s.err }
+
+func (s *cursor) Close() error {
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	s.cancel()
+	// every stage reads the rest of its source before closing its result,
+	// so all the stages have finished when resultC is closed
+	for range s.resultC {
+	}
+	return nil
+}
+
+// The stages read the rest of the source by these before closing the result,
+// not to block the upstream stages on sending forever.
+
+func drainRows(sourceC <-chan Row) {
+	for range sourceC {
+	}
+}
+
+func drainGRows(sourceC <-chan GRow) {
+	for range sourceC {
+	}
+}
@@ -0,0 +1,123 @@
+package eval_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/berquerant/dql/ast"
+	"github.com/berquerant/dql/cc"
+	"github.com/berquerant/dql/dig"
+	"github.com/berquerant/dql/eval"
+	"github.com/stretchr/testify/assert"
+)
+
+func parseStatement(t *testing.T, query string) *ast.Statement {
+	lexer := cc.NewLexer(strings.NewReader(query))
+	if status := cc.Parse(lexer); status != 0 {
+		t.Fatalf("failed to parse %s", query)
+	}
+	return lexer.Result().(*ast.Statement)
+}
+
+// waitGoroutines waits until the number of the goroutines is at most n.
+// The goroutines closing the channels may be still returning.
+func waitGoroutines(t *testing.T, n int) {
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > n {
+		if time.Now().After(deadline) {
+			buf := make([]byte, 1<<20)
+			buf = buf[:runtime.Stack(buf, true)]
+			t.Fatalf("%d goroutines leaked\n%s", runtime.NumGoroutine()-n, buf)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestCursor(t *testing.T) {
+	root := t.TempDir()
+	// more than the buffers of the stages
+	const fileCount = 2500
+	for i := 0; i < fileCount; i++ {
+		p := filepath.Join(root, fmt.Sprintf("d%d", i%5), fmt.Sprintf("f%d", i))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(fmt.Sprint(i)), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, tc := range []*struct {
+		title string
+		query string
+		opt   []eval.RunnerOption
+		dig   []dig.Option
+	}{
+		{title: "select", query: "select name;"},
+		{title: "where", query: "select name where not is_dir;"},
+		{title: "parallel", query: "select name where not is_dir;", opt: []eval.RunnerOption{eval.WithWorkers(4)}},
+		{title: "parallel dig", query: "select name;", dig: []dig.Option{dig.WithParallel(4)}},
+		{title: "unordered dig", query: "select name;", dig: []dig.Option{dig.WithParallel(4), dig.WithUnordered(true)}},
+		{title: "group by", query: "select is_dir, count(name) group by is_dir;"},
+		{title: "order by", query: "select name order by size;"},
+		{title: "top n", query: "select name order by size limit 2000;"},
+		{title: "limit", query: "select name limit 2000;"},
+		{title: "spill", query: "select name order by size;", opt: []eval.RunnerOption{eval.WithMemoryBudget(1), eval.WithTempDir(t.TempDir())}},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			newRunner := func() eval.Runner {
+				return eval.NewRunner(parseStatement(t, tc.query), dig.New(tc.dig...), tc.opt...)
+			}
+
+			t.Run("close early", func(t *testing.T) {
+				n := runtime.NumGoroutine()
+				cur := newRunner().Open(context.TODO(), root)
+				assert.True(t, cur.Next())
+				assert.NotNil(t, cur.Row())
+				assert.Nil(t, cur.Close())
+				waitGoroutines(t, n)
+				assert.False(t, cur.Next())
+				assert.Nil(t, cur.Err())
+				assert.Nil(t, cur.Close())
+			})
+
+			t.Run("read all", func(t *testing.T) {
+				n := runtime.NumGoroutine()
+				cur := newRunner().Open(context.TODO(), root)
+				var count int
+				for cur.Next() {
+					count++
+				}
+				assert.Nil(t, cur.Err())
+				assert.Less(t, 0, count)
+				waitGoroutines(t, n)
+			})
+
+			t.Run("cancel", func(t *testing.T) {
+				n := runtime.NumGoroutine()
+				ctx, cancel := context.WithCancel(context.TODO())
+				cur := newRunner().Open(ctx, root)
+				cancel()
+				for cur.Next() {
+				}
+				assert.True(t, cur.Err() == nil || strings.Contains(cur.Err().Error(), context.Canceled.Error()), "%v", cur.Err())
+				waitGoroutines(t, n)
+			})
+		})
+	}
+
+	t.Run("error", func(t *testing.T) {
+		n := runtime.NumGoroutine()
+		cur := eval.NewRunner(parseStatement(t, "select name where size;"), dig.New()).Open(context.TODO(), root)
+		assert.False(t, cur.Next())
+		assert.NotNil(t, cur.Err())
+		waitGoroutines(t, n)
+	})
+}
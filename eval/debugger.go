@@ -6,6 +6,7 @@ import (
 
 	"github.com/berquerant/dql/ast"
 	"github.com/berquerant/dql/cc"
+	"github.com/berquerant/dql/dig"
 	"github.com/berquerant/dql/logger"
 )
 
@@ -32,7 +33,7 @@ func (s *Debugger) Eval() {
 		return
 	}
 	stmt := s.Lexer.Result().(*ast.Statement)
-	for r := range NewRunner(stmt).Run(context.Background(), s.FileNames...) {
+	for r := range NewRunner(stmt, dig.New()).Run(context.Background(), s.FileNames...) {
 		if err := r.Err(); err != nil {
 			logger.Error("%v", err)
 			return
@@ -0,0 +1,171 @@
+package eval
+
+import (
+	"github.com/berquerant/dql/ast"
+	"github.com/berquerant/dql/calc"
+	"github.com/berquerant/dql/data"
+	"github.com/berquerant/dql/env"
+	"github.com/berquerant/dql/errors"
+)
+
+type (
+	// Evaluator prepares the evaluation of an expr for rows.
+	Evaluator interface {
+		// Prepare returns the evaluation of expr.
+		// table contains the values other than the columns, e.g. aliases.
+		Prepare(table env.Map, expr ast.Expr) Evaluation
+	}
+
+	// Evaluation evaluates an expr for rows.
+	// An Evaluation is not safe for concurrent use.
+	Evaluation interface {
+		// Row evaluates the expr for a row.
+		Row(row Row) (data.Data, error)
+		// GroupedRow evaluates the expr for a group.
+		// The columns except for the key are aggregated.
+		GroupedRow(row GroupedRow) (data.Data, error)
+		// Rows evaluates the expr for the rows, all columns are aggregated.
+		Rows(rows []Row) (data.Data, error)
+	}
+)
+
+// evalGRow evaluates the expr for a raw or a grouped row.
+func evalGRow(e Evaluation, row GRow) (data.Data, error) {
+	switch row.Type() {
+	case RawRowType:
+		return e.Row(row.Raw())
+	case GroupedRowType:
+		return e.GroupedRow(row.Grouped())
+	default:
+		return nil, errors.Wrap(ErrUnknownRowType, row.Type().String())
+	}
+}
+
+// NewInterpreter returns a new Evaluator that walks the expr for each row
+// with the table and the columns of the row.
+func NewInterpreter(calcFactory func(env.Map) calc.Calculator) Evaluator {
+	return &interpreter{
+		calcFactory: calcFactory,
+	}
+}
+
+type interpreter struct {
+	calcFactory func(env.Map) calc.Calculator
+}
+
+func (s *interpreter) Prepare(table env.Map, expr ast.Expr) Evaluation {
+	return &interpretation{
+		calcFactory: s.calcFactory,
+		table:       table,
+		expr:        expr,
+	}
+}
+
+type interpretation struct {
+	calcFactory func(env.Map) calc.Calculator
+	table       env.Map
+	expr        ast.Expr
+}
+
+func (s *interpretation) Row(row Row) (data.Data, error) {
+	return s.calcFactory(AppendRowToEnv(s.table, row)).Data(s.expr)
+}
+
+func (s *interpretation) GroupedRow(row GroupedRow) (data.Data, error) {
+	return s.calcFactory(AppendGroupedRowToEnv(s.table, row)).Data(s.expr)
+}
+
+func (s *interpretation) Rows(rows []Row) (data.Data, error) {
+	return s.calcFactory(AppendRowsToEnv(s.table, rows)).Data(s.expr)
+}
+
+// NewCompiledEvaluator returns a new Evaluator that compiles the expr once
+// and reads only the columns referred by the expr for each row.
+// The results are the same as NewInterpreter.
+func NewCompiledEvaluator(compilerFactory func(calc.Columns, env.Map) calc.Compiler) Evaluator {
+	return &compiledEvaluator{
+		compilerFactory: compilerFactory,
+	}
+}
+
+type compiledEvaluator struct {
+	compilerFactory func(calc.Columns, env.Map) calc.Compiler
+}
+
+func (s *compiledEvaluator) Prepare(table env.Map, expr ast.Expr) Evaluation {
+	return &compiledEvaluation{
+		program: s.compilerFactory(columnRegistry{}, table).Compile(expr),
+	}
+}
+
+type compiledEvaluation struct {
+	program calc.Program
+}
+
+func (s *compiledEvaluation) Row(row Row) (data.Data, error) {
+	return s.program(&rowFrame{
+		info: row.Info(),
+	})
+}
+
+func (s *compiledEvaluation) GroupedRow(row GroupedRow) (data.Data, error) {
+	keySlot, ok := columnSlots[row.Key()]
+	if !ok {
+		keySlot = -1
+	}
+	return s.program(&rowsFrame{
+		rows:    row.Rows(),
+		keySlot: keySlot,
+		key:     row.Value(),
+	})
+}
+
+func (s *compiledEvaluation) Rows(rows []Row) (data.Data, error) {
+	return s.program(&rowsFrame{
+		rows:    rows,
+		keySlot: -1,
+	})
+}
+
+// rowFrame is the columns of a row.
+type rowFrame struct {
+	info Info
+}
+
+func (s *rowFrame) Data(slot int) (data.Data, bool)  { return columnData(s.info, slot), true }
+func (*rowFrame) DataList(_ int) ([]data.Data, bool) { return nil, false }
+
+// rowsFrame is the aggregated columns of rows.
+type rowsFrame struct {
+	rows []Row
+	// keySlot is the slot of the group key, -1 if not grouped
+	keySlot int
+	key     data.Data
+	// lists are the aggregated columns read
+	lists [][]data.Data
+}
+
+func (s *rowsFrame) Data(slot int) (data.Data, bool) {
+	if slot == s.keySlot {
+		return s.key, true
+	}
+	return nil, false
+}
+
+func (s *rowsFrame) DataList(slot int) ([]data.Data, bool) {
+	if slot == s.keySlot {
+		return nil, false
+	}
+	if s.lists == nil {
+		s.lists = make([][]data.Data, len(columns))
+	}
+	if x := s.lists[slot]; x != nil {
+		return x, true
+	}
+	x := make([]data.Data, len(s.rows))
+	for i, r := range s.rows {
+		x[i] = columnData(r.Info(), slot)
+	}
+	s.lists[slot] = x
+	return x, true
+}
@@ -0,0 +1,110 @@
+package eval_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/berquerant/dql/calc"
+	"github.com/berquerant/dql/data"
+	"github.com/berquerant/dql/env"
+	"github.com/berquerant/dql/errors"
+	"github.com/berquerant/dql/eval"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompiledEvaluator(t *testing.T) {
+	var (
+		table = func() env.Map {
+			m := env.New()
+			m.Set("threshold", env.FromData(data.FromInt(100)))
+			m.Set("big", env.FromExpr(parseWhere(t, "size > threshold")))
+			m.Set("size", env.FromData(data.FromInt(-1)))
+			return m
+		}
+		rows = []eval.Row{
+			eval.NewRow(&mockInfo{name: "/r/a.go", size: 10, isDir: false, root: "/r", relName: "a.go", depth: 1}),
+			eval.NewRow(&mockInfo{name: "/r/d", size: 200, isDir: true, root: "/r", relName: "d", depth: 1}),
+			eval.NewRow(&mockInfo{name: "/r/d/b.txt", size: 300, isDir: false, root: "/r", relName: "d/b.txt", depth: 2}),
+		}
+		grouped = eval.NewGroupedRow("is_dir", data.FromBool(false), []eval.Row{rows[0], rows[2]})
+		// result is the value or whether an error occurred
+		result = func(d data.Data, err error) string {
+			if err != nil {
+				return "error"
+			}
+			return fmt.Sprintf("%s %v", d.Type(), d.Value())
+		}
+	)
+
+	for _, tc := range []*struct {
+		title      string
+		expr       string
+		aggregated bool
+	}{
+		{title: "column", expr: "size"},
+		{title: "column overrides alias", expr: "size + 1"},
+		{title: "arithmetic", expr: "size * 2 + depth_from_root"},
+		{title: "comparison", expr: `name = "/r/d" or size >= 300`},
+		{title: "logical", expr: "not is_dir and size < 100"},
+		{title: "xor", expr: "is_dir xor depth_from_root = 2"},
+		{title: "in", expr: "size in (10, 300)"},
+		{title: "not in", expr: "size not in (10, 300)"},
+		{title: "between", expr: "size between 100 and 250"},
+		{title: "like", expr: `name like "\.go$"`},
+		{title: "not like", expr: `rel_name not like "/"`},
+		{title: "function", expr: "depth(name)"},
+		{title: "nested function", expr: "len(base(name)) + 1"},
+		{title: "data alias", expr: "size < threshold"},
+		{title: "expr alias", expr: "big"},
+		{title: "unknown ident", expr: "unknown"},
+		{title: "unknown function", expr: "unknown(name)"},
+		{title: "type mismatch", expr: "is_dir and size"},
+		{title: "count", expr: "count(name)", aggregated: true},
+		{title: "sum", expr: "sum(size) + 1", aggregated: true},
+		{title: "aggregation of expr", expr: "max(size * 2 + 1)", aggregated: true},
+		{title: "aggregations", expr: "sum(size) / count(name)", aggregated: true},
+		{title: "group key", expr: "is_dir", aggregated: true},
+		{title: "aggregation of group key", expr: "count(is_dir)", aggregated: true},
+		{title: "column without aggregation", expr: "size", aggregated: true},
+		{title: "aggregation of multiple columns", expr: "sum(size + depth_from_root)", aggregated: true},
+		{title: "nested aggregation", expr: "sum(count(size))", aggregated: true},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			expr := parseWhere(t, tc.expr)
+			if !tc.aggregated {
+				var (
+					want = eval.NewInterpreter(calc.NewNormal).Prepare(table(), expr)
+					got  = eval.NewCompiledEvaluator(calc.NewNormalCompiler).Prepare(table(), expr)
+				)
+				for i, r := range rows {
+					assert.Equal(t, result(want.Row(r)), result(got.Row(r)), "row %d", i)
+				}
+				return
+			}
+			var (
+				want = eval.NewInterpreter(calc.NewAggregation).Prepare(table(), expr)
+				got  = eval.NewCompiledEvaluator(calc.NewAggregationCompiler).Prepare(table(), expr)
+			)
+			assert.Equal(t, result(want.GroupedRow(grouped)), result(got.GroupedRow(grouped)), "grouped")
+			assert.Equal(t, result(want.Rows(rows)), result(got.Rows(rows)), "rows")
+		})
+	}
+}
+
+// The interpreter does not terminate on these exprs.
+func TestCompiledEvaluatorNoTermination(t *testing.T) {
+	t.Run("cyclic alias", func(t *testing.T) {
+		table := env.New()
+		table.Set("x", env.FromExpr(parseWhere(t, "y + 1")))
+		table.Set("y", env.FromExpr(parseWhere(t, "x + 1")))
+		e := eval.NewCompiledEvaluator(calc.NewNormalCompiler).Prepare(table, parseWhere(t, "x"))
+		_, err := e.Row(eval.NewRow(&mockInfo{}))
+		assert.True(t, errors.Is(err, calc.ErrUnknownExpr))
+	})
+	t.Run("aggregation without column", func(t *testing.T) {
+		e := eval.NewCompiledEvaluator(calc.NewAggregationCompiler).Prepare(env.New(), parseWhere(t, "count(1)"))
+		_, err := e.Rows([]eval.Row{eval.NewRow(&mockInfo{})})
+		assert.True(t, errors.Is(err, calc.ErrUnknownExpr))
+	})
+}
@@ -2,6 +2,8 @@ package eval
 
 import (
 	"context"
+	"io"
+	"os"
 
 	"github.com/berquerant/dql/ast"
 	"github.com/berquerant/dql/async"
@@ -19,7 +21,8 @@ type (
 	groupByNoop struct{}
 
 	groupByKey struct {
-		key string
+		key   string
+		spill SpillConfig
 	}
 )
 
@@ -32,6 +35,19 @@ func NewGroupBy(key string) GroupBy {
 	}
 }
 
+// NewSpillGroupBy returns a new GroupBy that partitions the rows into temporary files by the key
+// when the rows exceed the memory budget, and groups each partition.
+// The groups are the same as NewGroupBy, each partition should fit in memory.
+func NewSpillGroupBy(key string, spill SpillConfig) GroupBy {
+	if key == "" {
+		return &groupByNoop{}
+	}
+	return &groupByKey{
+		key:   key,
+		spill: spill,
+	}
+}
+
 func (s *groupByKey) getKey(table env.Map) (string, error) {
 	v, ok := table.Get(s.key)
 	if !ok {
@@ -55,13 +71,36 @@ func (s *groupByKey) Group(ctx context.Context, table env.Map, sourceC <-chan Ro
 	key, err := s.getKey(table)
 	if err != nil {
 		resultC <- NewErrGRow(err)
-		close(resultC)
+		go func() {
+			defer close(resultC)
+			drainRows(sourceC)
+		}()
+		return resultC
+	}
+
+	slot, ok := columnSlots[key]
+	if !ok {
+		resultC <- NewErrGRow(errors.Wrap(ErrInvalidIdent, "group by key %s", key))
+		go func() {
+			defer close(resultC)
+			drainRows(sourceC)
+		}()
 		return resultC
 	}
 
 	go func() {
 		defer close(resultC)
-		d := map[interface{}][]Row{}
+		defer drainRows(sourceC)
+		var (
+			d     = map[interface{}][]Row{}
+			size  int
+			parts *groupPartitions
+		)
+		defer func() {
+			if parts != nil {
+				parts.close()
+			}
+		}()
 		for r := range sourceC {
 			if async.IsDone(ctx) {
 				resultC <- NewErrGRow(errors.Wrap(ctx.Err(), "group by"))
@@ -71,17 +110,47 @@ func (s *groupByKey) Group(ctx context.Context, table env.Map, sourceC <-chan Ro
 				resultC <- NewErrGRow(errors.Wrap(err, "group by"))
 				return
 			}
-			m := r.Info().ToMap()
-			k, exist := m[key]
-			if !exist {
-				resultC <- NewErrGRow(errors.Wrap(ErrInvalidIdent, "group by key %s", key))
-				return
+			// read the key only, not to read the other columns, e.g. the stats
+			kv := columnData(r.Info(), slot).Value()
+			if parts != nil {
+				if err := parts.add(kv, r); err != nil {
+					resultC <- NewErrGRow(errors.Wrap(err, "group by"))
+					return
+				}
+				continue
 			}
-			kv := k.Value()
 			if _, ok := d[kv]; !ok {
 				d[kv] = []Row{}
 			}
 			d[kv] = append(d[kv], r)
+			if !s.spill.enabled() {
+				continue
+			}
+			if size += estimateInfoSize(r.Info()); size < s.spill.MemoryBudget {
+				continue
+			}
+			// write the rows so far and the rest to the partitions
+			p, err := newGroupPartitions(s.spill)
+			if err != nil {
+				resultC <- NewErrGRow(errors.Wrap(err, "group by"))
+				return
+			}
+			parts = p
+			for k, rows := range d {
+				for _, x := range rows {
+					if err := parts.add(k, x); err != nil {
+						resultC <- NewErrGRow(errors.Wrap(err, "group by"))
+						return
+					}
+				}
+			}
+			d = nil
+		}
+		if parts != nil {
+			if err := parts.group(ctx, key, resultC); err != nil {
+				resultC <- NewErrGRow(errors.Wrap(err, "group by"))
+			}
+			return
 		}
 		for k, rows := range d {
 			kk, _ := data.FromInterface(k)
@@ -95,6 +164,7 @@ func (*groupByNoop) Group(ctx context.Context, _ env.Map, sourceC <-chan Row) <-
 	resultC := make(chan GRow, 1000)
 	go func() {
 		defer close(resultC)
+		defer drainRows(sourceC)
 		for r := range sourceC {
 			if async.IsDone(ctx) {
 				resultC <- NewErrGRow(errors.Wrap(ctx.Err(), "group by (noop)"))
@@ -109,3 +179,96 @@ func (*groupByNoop) Group(ctx context.Context, _ env.Map, sourceC <-chan Row) <-
 	}()
 	return resultC
 }
+
+// groupPartitions is the rows partitioned by the group key into temporary files.
+type groupPartitions struct {
+	dir      string
+	writers  []*spillWriter
+	skipStat bool
+}
+
+func newGroupPartitions(spill SpillConfig) (*groupPartitions, error) {
+	dir, err := spill.mkdirTemp()
+	if err != nil {
+		return nil, err
+	}
+	return &groupPartitions{
+		dir:      dir,
+		writers:  make([]*spillWriter, spillPartitions),
+		skipStat: spill.SkipStat,
+	}, nil
+}
+
+func (s *groupPartitions) close() {
+	for _, w := range s.writers {
+		if w != nil {
+			w.Close()
+		}
+	}
+	os.RemoveAll(s.dir)
+}
+
+// groupPartitionRecord is a row of a partition.
+type groupPartitionRecord struct {
+	Key  interface{}
+	Info *spilledInfo
+}
+
+func (s *groupPartitions) add(key interface{}, row Row) error {
+	i := partitionOf(key)
+	if s.writers[i] == nil {
+		w, err := newSpillWriter(spillFileName(s.dir, i))
+		if err != nil {
+			return err
+		}
+		s.writers[i] = w
+	}
+	return s.writers[i].Write(&groupPartitionRecord{
+		Key:  key,
+		Info: newSpilledInfo(row.Info(), s.skipStat),
+	})
+}
+
+// group sends the groups of each partition.
+func (s *groupPartitions) group(ctx context.Context, key string, resultC chan<- GRow) error {
+	for i, w := range s.writers {
+		if w == nil {
+			continue
+		}
+		s.writers[i] = nil
+		if err := w.Close(); err != nil {
+			return err
+		}
+		d, err := s.read(spillFileName(s.dir, i))
+		if err != nil {
+			return err
+		}
+		if async.IsDone(ctx) {
+			return ctx.Err()
+		}
+		for k, rows := range d {
+			kk, _ := data.FromInterface(k)
+			resultC <- NewGroupedGRow(NewGroupedRow(key, kk, rows))
+		}
+	}
+	return nil
+}
+
+func (s *groupPartitions) read(name string) (map[interface{}][]Row, error) {
+	r, err := newSpillReader(name)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	d := map[interface{}][]Row{}
+	for {
+		var rec groupPartitionRecord
+		if err := r.Read(&rec); err != nil {
+			if errors.Is(err, io.EOF) {
+				return d, nil
+			}
+			return nil, err
+		}
+		d[rec.Key] = append(d[rec.Key], NewRow(&restoredInfo{v: rec.Info}))
+	}
+}
@@ -110,6 +110,22 @@ func TestGroupBy(t *testing.T) {
 			}
 		})
 
+		t.Run("read key only", func(t *testing.T) {
+			got := resultToGRows(eval.NewGroupBy("is_dir").Group(context.TODO(), env.New(), yield(
+				eval.NewRow(&isDirOnlyInfo{isDir: true}),
+				eval.NewRow(&isDirOnlyInfo{isDir: false}),
+				eval.NewRow(&isDirOnlyInfo{isDir: true}),
+			)))
+			sizes := map[interface{}]int{}
+			for _, row := range got {
+				if !assert.Equal(t, eval.GroupedRowType, row.Type()) {
+					return
+				}
+				sizes[row.Grouped().Value().Value()] = len(row.Grouped().Rows())
+			}
+			assert.Equal(t, map[interface{}]int{true: 2, false: 1}, sizes)
+		})
+
 		t.Run("err row", func(t *testing.T) {
 			got := resultToGRows(eval.NewGroupBy("name").Group(context.TODO(), env.New(), yield(errRow)))
 			assert.Equal(t, 1, len(got))
@@ -194,3 +210,12 @@ func TestGroupBy(t *testing.T) {
 		})
 	})
 }
+
+// isDirOnlyInfo has is_dir only.
+// Reading the other columns panics because Info is nil.
+type isDirOnlyInfo struct {
+	eval.Info
+	isDir bool
+}
+
+func (s *isDirOnlyInfo) IsDir() bool { return s.isDir }
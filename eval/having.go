@@ -5,7 +5,6 @@ import (
 
 	"github.com/berquerant/dql/ast"
 	"github.com/berquerant/dql/async"
-	"github.com/berquerant/dql/calc"
 	"github.com/berquerant/dql/data"
 	"github.com/berquerant/dql/env"
 	"github.com/berquerant/dql/errors"
@@ -18,20 +17,22 @@ type (
 	}
 
 	having struct {
-		calcFactory func(env.Map) calc.Calculator
+		evaluator Evaluator
 	}
 )
 
-func NewHaving(calcFactory func(env.Map) calc.Calculator) Having {
+func NewHaving(evaluator Evaluator) Having {
 	return &having{
-		calcFactory: calcFactory,
+		evaluator: evaluator,
 	}
 }
 
 func (s *having) Filter(ctx context.Context, table env.Map, expr ast.Expr, sourceC <-chan GRow) <-chan GRow {
 	resultC := make(chan GRow, resultCBufferSize)
+	e := s.evaluator.Prepare(table, expr)
 	go func() {
 		defer close(resultC)
+		defer drainGRows(sourceC)
 		for row := range sourceC {
 			if async.IsDone(ctx) {
 				resultC <- NewErrGRow(errors.Wrap(ctx.Err(), "having"))
@@ -46,8 +47,7 @@ func (s *having) Filter(ctx context.Context, table env.Map, expr ast.Expr, sourc
 				resultC <- NewErrGRow(errors.Wrap(ErrInvalidHaving, "row type %s %s", row.Type(), logger.JSON(row)))
 				return
 			}
-			t := AppendGroupedRowToEnv(table, row.Grouped())
-			err := s.filter(t, expr)
+			err := s.filter(e, row.Grouped())
 			switch {
 			case err == nil:
 				// accepted
@@ -64,8 +64,8 @@ func (s *having) Filter(ctx context.Context, table env.Map, expr ast.Expr, sourc
 	return resultC
 }
 
-func (s *having) filter(table env.Map, expr ast.Expr) error {
-	r, err := s.calcFactory(table).Data(expr)
+func (s *having) filter(e Evaluation, row GroupedRow) error {
+	r, err := e.GroupedRow(row)
 	if err != nil {
 		return err
 	}
@@ -14,10 +14,10 @@ import (
 
 func TestHaving(t *testing.T) {
 	var (
-		factory = func(c calc.Calculator) func(env.Map) calc.Calculator {
-			return func(_ env.Map) calc.Calculator {
+		factory = func(c calc.Calculator) eval.Evaluator {
+			return eval.NewInterpreter(func(_ env.Map) calc.Calculator {
 				return c
-			}
+			})
 		}
 		infoRawGRow     = eval.NewRawGRow(eval.NewRow(&mockInfo{name: "mock"}))
 		infoGroupedGRow = eval.NewGroupedGRow(eval.NewGroupedRow("mock", data.FromString("mmm"), []eval.Row{
@@ -12,31 +12,57 @@ type (
 		Limit(ctx context.Context, limit, offset int, sourceC <-chan GRow) <-chan GRow
 	}
 
-	limit struct{}
+	limit struct {
+		stop func()
+	}
 )
 
-func NewLimit() Limit { return &limit{} }
+// NewLimit returns a new Limit.
+// stop is called when no more rows are required, to stop the upstream stages.
+// The rows from sourceC after that are still drained and discarded.
+// stop can be nil.
+func NewLimit(stop func()) Limit {
+	if stop == nil {
+		stop = func() {}
+	}
+	return &limit{
+		stop: stop,
+	}
+}
 
-func (*limit) Limit(ctx context.Context, limit, offset int, sourceC <-chan GRow) <-chan GRow {
+func (s *limit) Limit(ctx context.Context, limit, offset int, sourceC <-chan GRow) <-chan GRow {
 	resultC := make(chan GRow, resultCBufferSize)
 	if limit < 1 || offset < 0 {
+		s.stop()
 		resultC <- NewErrGRow(errors.Wrap(ErrInvalidLimit, "limit %d offset %d", limit, offset))
-		close(resultC)
+		go func() {
+			defer close(resultC)
+			drainGRows(sourceC)
+		}()
 		return resultC
 	}
 	go func() {
 		defer close(resultC)
+		defer drainGRows(sourceC)
+		defer s.stop()
 		var (
 			i, c int
 		)
 		for r := range sourceC {
+			if c >= limit {
+				// drain the rows sent before the upstream stopped
+				continue
+			}
 			if async.IsDone(ctx) {
 				resultC <- NewErrGRow(errors.Wrap(ctx.Err(), "limit"))
 				return
 			}
-			if i >= offset && c < limit {
+			if i >= offset {
 				resultC <- r
 				c++
+				if c >= limit {
+					s.stop()
+				}
 			}
 			i++
 		}
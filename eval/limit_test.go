@@ -78,7 +78,7 @@ func TestLimit(t *testing.T) {
 		} {
 			tc := tc
 			t.Run(tc.title, func(t *testing.T) {
-				got := resultToGRows(eval.NewLimit().Limit(context.TODO(), tc.limit, tc.offset, yield(tc.rows)))
+				got := resultToGRows(eval.NewLimit(nil).Limit(context.TODO(), tc.limit, tc.offset, yield(tc.rows)))
 				assert.Equal(t, len(tc.want), len(got))
 				for i, w := range tc.want {
 					g := got[i].Raw().Info().Name()
@@ -88,14 +88,52 @@ func TestLimit(t *testing.T) {
 		}
 	})
 
+	t.Run("stop", func(t *testing.T) {
+		for _, tc := range []*struct {
+			title         string
+			rows          []eval.GRow
+			limit, offset int
+			want          []string
+			stopped       bool
+		}{
+			{
+				title:   "enough rows",
+				rows:    append(makeRows(3), eval.NewErrGRow(context.Canceled)),
+				limit:   2,
+				offset:  1,
+				want:    []string{"1", "2"},
+				stopped: true,
+			},
+			{
+				title:   "not enough rows",
+				rows:    makeRows(3),
+				limit:   5,
+				want:    []string{"0", "1", "2"},
+				stopped: true,
+			},
+		} {
+			tc := tc
+			t.Run(tc.title, func(t *testing.T) {
+				var stopped bool
+				got := resultToGRows(eval.NewLimit(func() { stopped = true }).Limit(context.TODO(), tc.limit, tc.offset, yield(tc.rows)))
+				assert.Equal(t, len(tc.want), len(got))
+				for i, w := range tc.want {
+					assert.Nil(t, got[i].Err())
+					assert.Equal(t, w, got[i].Raw().Info().Name())
+				}
+				assert.Equal(t, tc.stopped, stopped)
+			})
+		}
+	})
+
 	t.Run("invalid limit", func(t *testing.T) {
-		got := resultToGRows(eval.NewLimit().Limit(context.TODO(), 0, 0, yield(makeRows(1))))
+		got := resultToGRows(eval.NewLimit(nil).Limit(context.TODO(), 0, 0, yield(makeRows(1))))
 		assert.Equal(t, 1, len(got))
 		assert.ErrorIs(t, got[0].Err(), eval.ErrInvalidLimit)
 	})
 
 	t.Run("invalid offset", func(t *testing.T) {
-		got := resultToGRows(eval.NewLimit().Limit(context.TODO(), 1, -1, yield(makeRows(1))))
+		got := resultToGRows(eval.NewLimit(nil).Limit(context.TODO(), 1, -1, yield(makeRows(1))))
 		assert.Equal(t, 1, len(got))
 		assert.ErrorIs(t, got[0].Err(), eval.ErrInvalidLimit)
 	})
@@ -1,12 +1,14 @@
 package eval
 
 import (
+	"container/heap"
 	"context"
+	"io"
+	"os"
 	"sort"
 
 	"github.com/berquerant/dql/ast"
 	"github.com/berquerant/dql/async"
-	"github.com/berquerant/dql/calc"
 	"github.com/berquerant/dql/data"
 	"github.com/berquerant/dql/env"
 	"github.com/berquerant/dql/errors"
@@ -18,7 +20,8 @@ type (
 	}
 
 	orderBy struct {
-		calcFactory func(env.Map) calc.Calculator
+		evaluator Evaluator
+		spill     SpillConfig
 	}
 
 	orderByRow struct {
@@ -27,9 +30,19 @@ type (
 	}
 )
 
-func NewOrderBy(calcFactory func(env.Map) calc.Calculator) OrderBy {
+func NewOrderBy(evaluator Evaluator) OrderBy {
 	return &orderBy{
-		calcFactory: calcFactory,
+		evaluator: evaluator,
+	}
+}
+
+// NewSpillOrderBy returns a new OrderBy that writes the sorted runs of rows to temporary files
+// when the rows exceed the memory budget, and merges them.
+// The result is the same as NewOrderBy.
+func NewSpillOrderBy(evaluator Evaluator, spill SpillConfig) OrderBy {
+	return &orderBy{
+		evaluator: evaluator,
+		spill:     spill,
 	}
 }
 
@@ -37,20 +50,65 @@ func (s *orderBy) Sort(
 	ctx context.Context, table env.Map, expr ast.Expr, isDesc bool, sourceC <-chan GRow,
 ) <-chan GRow {
 	resultC := make(chan GRow, resultCBufferSize)
+	e := s.evaluator.Prepare(table, expr)
 	go func() {
 		defer close(resultC)
-		rows := []*orderByRow{}
+		defer drainGRows(sourceC)
+		var (
+			rows = []*orderByRow{}
+			size int
+			runs *sortRuns
+		)
+		defer func() {
+			if runs != nil {
+				runs.close()
+			}
+		}()
 		for r := range sourceC {
 			if async.IsDone(ctx) {
 				resultC <- NewErrGRow(errors.Wrap(ctx.Err(), "order by"))
 				return
 			}
-			v, err := s.evalRow(table, expr, r)
+			v, err := s.evalRow(e, r)
 			if err != nil {
 				resultC <- NewErrGRow(errors.Wrap(err, "order by"))
 				return
 			}
 			rows = append(rows, v)
+			if !s.spill.enabled() {
+				continue
+			}
+			if size += estimateGRowSize(r); size < s.spill.MemoryBudget {
+				continue
+			}
+			if runs == nil {
+				// the type of the first row decides the order
+				less, err := lessFunc(rows[0].value.Type(), isDesc)
+				if err != nil {
+					resultC <- NewErrGRow(err)
+					return
+				}
+				if runs, err = newSortRuns(s.spill, less); err != nil {
+					resultC <- NewErrGRow(errors.Wrap(err, "order by"))
+					return
+				}
+			}
+			if err := runs.add(rows); err != nil {
+				resultC <- NewErrGRow(errors.Wrap(err, "order by"))
+				return
+			}
+			rows = []*orderByRow{}
+			size = 0
+		}
+		if runs != nil {
+			if err := runs.add(rows); err != nil {
+				resultC <- NewErrGRow(errors.Wrap(err, "order by"))
+				return
+			}
+			if err := runs.merge(ctx, resultC); err != nil {
+				resultC <- NewErrGRow(errors.Wrap(err, "order by"))
+			}
+			return
 		}
 		if len(rows) == 0 {
 			return
@@ -69,37 +127,36 @@ func (s *orderBy) Sort(
 }
 
 func (s *orderBy) getSortFunc(rows []*orderByRow, isDesc bool) (func(int, int) bool, error) {
-	f, err := s.sortFunc(rows)
+	f, err := lessFunc(rows[0].value.Type(), isDesc)
 	if err != nil {
 		return nil, err
 	}
-	if isDesc {
-		return func(i, j int) bool { return f(j, i) }, nil
-	}
-	return f, nil
+	return func(i, j int) bool { return f(rows[i].value, rows[j].value) }, nil
 }
 
-func (*orderBy) sortFunc(rows []*orderByRow) (func(int, int) bool, error) {
-	switch rows[0].value.Type() {
+// lessFunc returns the function to compare the values of the type.
+func lessFunc(typ data.Type, isDesc bool) (func(a, b data.Data) bool, error) {
+	var f func(a, b data.Data) bool
+	switch typ {
 	case data.TypeInt:
-		return func(i, j int) bool { return rows[i].value.Int() < rows[j].value.Int() }, nil
+		f = func(a, b data.Data) bool { return a.Int() < b.Int() }
 	case data.TypeString:
-		return func(i, j int) bool { return rows[i].value.String() < rows[j].value.String() }, nil
+		f = func(a, b data.Data) bool { return a.String() < b.String() }
 	case data.TypeFloat:
-		return func(i, j int) bool { return rows[i].value.Float() < rows[j].value.Float() }, nil
+		f = func(a, b data.Data) bool { return a.Float() < b.Float() }
 	case data.TypeBool:
-		return func(i, j int) bool { return !rows[i].value.Bool() && rows[j].value.Bool() }, nil
+		f = func(a, b data.Data) bool { return !a.Bool() && b.Bool() }
 	default:
 		return nil, errors.Wrap(ErrUnknownDataType, "order by")
 	}
+	if isDesc {
+		return func(a, b data.Data) bool { return f(b, a) }, nil
+	}
+	return f, nil
 }
 
-func (s *orderBy) evalRow(table env.Map, expr ast.Expr, row GRow) (*orderByRow, error) {
-	t, err := AppendGRowToEnv(table, row)
-	if err != nil {
-		return nil, err
-	}
-	v, err := s.calcFactory(t).Data(expr)
+func (s *orderBy) evalRow(e Evaluation, row GRow) (*orderByRow, error) {
+	v, err := evalGRow(e, row)
 	if err != nil {
 		return nil, err
 	}
@@ -108,3 +165,229 @@ func (s *orderBy) evalRow(table env.Map, expr ast.Expr, row GRow) (*orderByRow,
 		value: v,
 	}, nil
 }
+
+// sortRuns is the sorted runs of rows written to temporary files.
+type sortRuns struct {
+	dir   string
+	less  func(a, b data.Data) bool
+	files []string // runs in the order of the rows
+	n     int      // number of the files written
+	// skipStat is true if the rows are written without the stats
+	skipStat bool
+}
+
+func newSortRuns(spill SpillConfig, less func(a, b data.Data) bool) (*sortRuns, error) {
+	dir, err := spill.mkdirTemp()
+	if err != nil {
+		return nil, err
+	}
+	return &sortRuns{
+		dir:      dir,
+		less:     less,
+		skipStat: spill.SkipStat,
+	}, nil
+}
+
+func (s *sortRuns) close() { os.RemoveAll(s.dir) }
+
+// sortRunRecord is a row of a sorted run.
+type sortRunRecord struct {
+	Value interface{}
+	Row   *spilledGRow
+}
+
+// add sorts rows and writes them as a new run.
+func (s *sortRuns) add(rows []*orderByRow) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	sort.SliceStable(rows, func(i, j int) bool { return s.less(rows[i].value, rows[j].value) })
+	w, err := s.newRun()
+	if err != nil {
+		return err
+	}
+	for _, r := range rows {
+		x, err := newSpilledGRow(r.row, s.skipStat)
+		if err != nil {
+			w.Close()
+			return err
+		}
+		if err := w.Write(&sortRunRecord{
+			Value: r.value.Value(),
+			Row:   x,
+		}); err != nil {
+			w.Close()
+			return err
+		}
+	}
+	return w.Close()
+}
+
+// newRun appends a new run file.
+func (s *sortRuns) newRun() (*spillWriter, error) {
+	name := spillFileName(s.dir, s.n)
+	w, err := newSpillWriter(name)
+	if err != nil {
+		return nil, err
+	}
+	s.n++
+	s.files = append(s.files, name)
+	return w, nil
+}
+
+// maxMergeRuns is the maximum number of the runs opened at once to merge.
+const maxMergeRuns = 64
+
+// merge sends the rows of all runs in order.
+// The rows with the same value are sent in the order of the runs, to keep the sort stable.
+//
+// If there are more than maxMergeRuns runs, the first runs are merged into a new run
+// that takes their place, until the rest can be opened at once.
+func (s *sortRuns) merge(ctx context.Context, resultC chan<- GRow) error {
+	for len(s.files) > maxMergeRuns {
+		if err := s.compact(ctx); err != nil {
+			return err
+		}
+	}
+	return mergeSortRuns(ctx, s.files, s.less, func(rec *sortRunRecord) error {
+		row, err := rec.Row.restore()
+		if err != nil {
+			return err
+		}
+		resultC <- row
+		return nil
+	})
+}
+
+// compact merges the first maxMergeRuns runs into a run.
+func (s *sortRuns) compact(ctx context.Context) error {
+	var (
+		files = s.files[:maxMergeRuns]
+		rest  = s.files[maxMergeRuns:]
+	)
+	s.files = nil
+	w, err := s.newRun()
+	if err != nil {
+		return err
+	}
+	if err := mergeSortRuns(ctx, files, s.less, func(rec *sortRunRecord) error {
+		return w.Write(rec)
+	}); err != nil {
+		w.Close()
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	for _, f := range files {
+		os.Remove(f)
+	}
+	s.files = append(s.files, rest...)
+	return nil
+}
+
+// mergeSortRuns passes the rows of the runs to f in order.
+func mergeSortRuns(ctx context.Context, files []string, less func(a, b data.Data) bool, f func(*sortRunRecord) error) error {
+	h := &sortRunHeap{
+		less: less,
+	}
+	defer func() {
+		for _, x := range h.heads {
+			x.r.Close()
+		}
+	}()
+	for i, name := range files {
+		r, err := newSpillReader(name)
+		if err != nil {
+			return err
+		}
+		x := &sortRunHead{
+			r:   r,
+			run: i,
+		}
+		ok, err := x.next()
+		if err != nil {
+			r.Close()
+			return err
+		}
+		if !ok {
+			r.Close()
+			continue
+		}
+		h.heads = append(h.heads, x)
+	}
+	heap.Init(h)
+	for h.Len() > 0 {
+		if async.IsDone(ctx) {
+			return ctx.Err()
+		}
+		x := h.heads[0]
+		if err := f(x.rec); err != nil {
+			return err
+		}
+		ok, err := x.next()
+		if err != nil {
+			return err
+		}
+		if ok {
+			heap.Fix(h, 0)
+			continue
+		}
+		heap.Pop(h)
+		x.r.Close()
+	}
+	return nil
+}
+
+// sortRunHead is the current row of a run.
+type sortRunHead struct {
+	r     *spillReader
+	run   int
+	rec   *sortRunRecord
+	value data.Data
+}
+
+// next reads the next row of the run.
+// Returns false if no more rows.
+func (s *sortRunHead) next() (bool, error) {
+	var rec sortRunRecord
+	if err := s.r.Read(&rec); err != nil {
+		if errors.Is(err, io.EOF) {
+			return false, nil
+		}
+		return false, err
+	}
+	v, ok := data.FromInterface(rec.Value)
+	if !ok {
+		return false, errors.Wrap(ErrUnknownDataType, "order by restore %v", rec.Value)
+	}
+	s.rec = &rec
+	s.value = v
+	return true, nil
+}
+
+type sortRunHeap struct {
+	heads []*sortRunHead
+	less  func(a, b data.Data) bool
+}
+
+func (s *sortRunHeap) Len() int { return len(s.heads) }
+func (s *sortRunHeap) Less(i, j int) bool {
+	a, b := s.heads[i], s.heads[j]
+	if s.less(a.value, b.value) {
+		return true
+	}
+	if s.less(b.value, a.value) {
+		return false
+	}
+	return a.run < b.run
+}
+func (s *sortRunHeap) Swap(i, j int)      { s.heads[i], s.heads[j] = s.heads[j], s.heads[i] }
+func (s *sortRunHeap) Push(x interface{}) { s.heads = append(s.heads, x.(*sortRunHead)) }
+func (s *sortRunHeap) Pop() interface{} {
+	n := len(s.heads)
+	x := s.heads[n-1]
+	s.heads[n-1] = nil
+	s.heads = s.heads[:n-1]
+	return x
+}
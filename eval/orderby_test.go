@@ -26,10 +26,10 @@ func (s *mockMultipleCalculator) Data(_ ast.Expr) (data.Data, error) {
 
 func TestOrderBy(t *testing.T) {
 	var (
-		factory = func(c calc.Calculator) func(env.Map) calc.Calculator {
-			return func(_ env.Map) calc.Calculator {
+		factory = func(c calc.Calculator) eval.Evaluator {
+			return eval.NewInterpreter(func(_ env.Map) calc.Calculator {
 				return c
-			}
+			})
 		}
 		makeRows = func(names ...string) []eval.GRow {
 			rows := make([]eval.GRow, len(names))
@@ -0,0 +1,179 @@
+package eval_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/berquerant/dql/ast"
+	"github.com/berquerant/dql/data"
+	"github.com/berquerant/dql/env"
+	"github.com/berquerant/dql/errors"
+	"github.com/berquerant/dql/eval"
+	"github.com/stretchr/testify/assert"
+)
+
+var errMockEvaluation = errors.New("mock evaluation")
+
+// mockSlowEvaluator evaluates whether the size is even after sleeping the size in milliseconds,
+// so the later rows may be evaluated earlier.
+// Fails if the size is negative.
+type mockSlowEvaluator struct{}
+
+func (mockSlowEvaluator) Prepare(_ env.Map, _ ast.Expr) eval.Evaluation { return &mockSlowEvaluation{} }
+
+type mockSlowEvaluation struct{}
+
+func (*mockSlowEvaluation) Row(row eval.Row) (data.Data, error) {
+	size := row.Info().Size()
+	if size < 0 {
+		return nil, errMockEvaluation
+	}
+	time.Sleep(time.Duration(size) * time.Millisecond)
+	return data.FromBool(size%2 == 0), nil
+}
+func (*mockSlowEvaluation) GroupedRow(_ eval.GroupedRow) (data.Data, error) {
+	return nil, errMockEvaluation
+}
+func (*mockSlowEvaluation) Rows(_ []eval.Row) (data.Data, error) { return nil, errMockEvaluation }
+
+func TestParallelWhere(t *testing.T) {
+	var (
+		yield = func(sizes []int) <-chan eval.Row {
+			c := make(chan eval.Row, len(sizes))
+			for i, x := range sizes {
+				c <- eval.NewRow(&mockInfo{
+					name: fmt.Sprint(i),
+					size: x,
+				})
+			}
+			close(c)
+			return c
+		}
+		names = func(resultC <-chan eval.Row) []string {
+			r := []string{}
+			for x := range resultC {
+				if err := x.Err(); err != nil {
+					r = append(r, "error")
+					continue
+				}
+				r = append(r, x.Info().Name())
+			}
+			return r
+		}
+	)
+
+	for _, tc := range []*struct {
+		title string
+		sizes []int
+		want  []string
+	}{
+		{
+			title: "empty",
+			want:  []string{},
+		},
+		{
+			title: "filter",
+			sizes: []int{20, 2, 15, 4, 0, 10, 1, 6},
+			want:  []string{"0", "1", "3", "4", "5", "7"},
+		},
+		{
+			title: "error",
+			sizes: []int{20, 2, 15, -1, 0, 10},
+			want:  []string{"0", "1", "error"},
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			want := names(eval.NewWhere(mockSlowEvaluator{}).Filter(context.TODO(), env.New(), nil, yield(tc.sizes)))
+			assert.Equal(t, tc.want, want)
+			for _, workers := range []int{0, 2, 4, 16} {
+				got := names(eval.NewParallelWhere(mockSlowEvaluator{}, workers).Filter(context.TODO(), env.New(), nil, yield(tc.sizes)))
+				assert.Equal(t, want, got, "workers %d", workers)
+			}
+		})
+	}
+
+	t.Run("concurrent", func(t *testing.T) {
+		sizes := make([]int, 16)
+		for i := range sizes {
+			sizes[i] = 50
+		}
+		start := time.Now()
+		got := names(eval.NewParallelWhere(mockSlowEvaluator{}, len(sizes)).Filter(context.TODO(), env.New(), nil, yield(sizes)))
+		assert.Equal(t, len(sizes), len(got))
+		assert.Less(t, int64(time.Since(start)), int64(50*time.Millisecond*time.Duration(len(sizes)/2)))
+	})
+}
+
+func TestParallelSelect(t *testing.T) {
+	var (
+		yield = func(rows []eval.GRow) <-chan eval.GRow {
+			c := make(chan eval.GRow, len(rows))
+			for _, r := range rows {
+				c <- r
+			}
+			close(c)
+			return c
+		}
+		rawRows = func(sizes ...int) []eval.GRow {
+			r := make([]eval.GRow, len(sizes))
+			for i, x := range sizes {
+				r[i] = eval.NewRawGRow(eval.NewRow(&mockInfo{
+					name: fmt.Sprint(i),
+					size: x,
+				}))
+			}
+			return r
+		}
+		values = func(resultC <-chan eval.SRow) []string {
+			r := []string{}
+			for x := range resultC {
+				if err := x.Err(); err != nil {
+					r = append(r, "error")
+					continue
+				}
+				r = append(r, fmt.Sprint(x.Get(0).Value(), x.Get(1).Value()))
+			}
+			return r
+		}
+	)
+
+	for _, tc := range []*struct {
+		title string
+		rows  []eval.GRow
+		want  []string
+	}{
+		{
+			title: "empty",
+			want:  []string{},
+		},
+		{
+			title: "select",
+			rows:  rawRows(20, 3, 15, 4, 0, 10),
+			want:  []string{"true true", "false false", "false false", "true true", "true true", "true true"},
+		},
+		{
+			title: "error",
+			rows:  rawRows(20, 3, -1, 4),
+			want:  []string{"true true", "false false", "error"},
+		},
+		{
+			title: "error row",
+			rows:  append(rawRows(20, 3), eval.NewErrGRow(errMockEvaluation)),
+			want:  []string{"true true", "false false", "error"},
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			exprs := []ast.Expr{parseWhere(t, "size"), parseWhere(t, "name")}
+			want := values(eval.NewSelect(mockSlowEvaluator{}, exprs).Select(context.TODO(), env.New(), yield(tc.rows)))
+			assert.Equal(t, tc.want, want)
+			for _, workers := range []int{2, 4, 16} {
+				got := values(eval.NewParallelSelect(mockSlowEvaluator{}, exprs, workers).Select(context.TODO(), env.New(), yield(tc.rows)))
+				assert.Equal(t, want, got, "workers %d", workers)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package eval
+
+import "sync"
+
+// orderedWindowFactor limits the number of the items being evaluated or waiting for the preceding items
+// to workers * orderedWindowFactor.
+const orderedWindowFactor = 4
+
+// seqItem is an item with the sequence number of the input.
+type seqItem struct {
+	seq   int
+	value interface{}
+}
+
+// runOrdered reads the items by next until it returns false, applies f to them with the workers concurrently
+// and calls emit with the results in the order of the items.
+// worker of f is the index of the worker, to use the values not safe for concurrent use.
+// Stops reading the items when emit returns false.
+// All the goroutines have finished when this returns.
+func runOrdered(
+	workers int,
+	next func() (interface{}, bool),
+	f func(worker int, item interface{}) interface{},
+	emit func(result interface{}) bool,
+) {
+	var (
+		jobC   = make(chan *seqItem, workers)
+		doneC  = make(chan *seqItem, workers)
+		stopC  = make(chan struct{})
+		window = make(chan struct{}, workers*orderedWindowFactor)
+		wg     sync.WaitGroup
+	)
+
+	go func() {
+		defer close(jobC)
+		for seq := 0; ; seq++ {
+			select {
+			case window <- struct{}{}:
+			case <-stopC:
+				return
+			}
+			x, ok := next()
+			if !ok {
+				return
+			}
+			select {
+			case jobC <- &seqItem{seq: seq, value: x}:
+			case <-stopC:
+				return
+			}
+		}
+	}()
+
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		worker := i
+		go func() {
+			defer wg.Done()
+			for job := range jobC {
+				doneC <- &seqItem{
+					seq:   job.seq,
+					value: f(worker, job.value),
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(doneC)
+	}()
+
+	var (
+		pending = map[int]interface{}{}
+		nextSeq int
+	)
+	for r := range doneC {
+		pending[r.seq] = r.value
+		for {
+			v, ok := pending[nextSeq]
+			if !ok {
+				break
+			}
+			delete(pending, nextSeq)
+			nextSeq++
+			<-window
+			if !emit(v) {
+				close(stopC)
+				// wait for the dispatcher and the workers evaluating the items
+				for range doneC {
+				}
+				return
+			}
+		}
+	}
+}
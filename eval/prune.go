@@ -0,0 +1,37 @@
+package eval
+
+import (
+	"github.com/berquerant/dql/ast"
+	"github.com/berquerant/dql/data"
+	"github.com/berquerant/dql/env"
+	"github.com/berquerant/dql/errors"
+)
+
+type (
+	// Pruner decides whether to dig the directory or not.
+	Pruner interface {
+		// Prune returns true if the directory and its descendants should be skipped.
+		Prune(row Row) (bool, error)
+	}
+
+	pruner struct {
+		expr Evaluation
+	}
+)
+
+func NewPruner(evaluator Evaluator, table env.Map, expr ast.Expr) Pruner {
+	return &pruner{
+		expr: evaluator.Prepare(table, expr),
+	}
+}
+
+func (s *pruner) Prune(row Row) (bool, error) {
+	r, err := s.expr.Row(row)
+	if err != nil {
+		return false, errors.Wrap(err, "prune")
+	}
+	if r.Type() != data.TypeBool {
+		return false, errors.Wrap(ErrNotBoolExpr, "prune")
+	}
+	return r.Bool(), nil
+}
@@ -0,0 +1,66 @@
+package eval_test
+
+import (
+	"testing"
+
+	"github.com/berquerant/dql/calc"
+	"github.com/berquerant/dql/data"
+	"github.com/berquerant/dql/env"
+	"github.com/berquerant/dql/errors"
+	"github.com/berquerant/dql/eval"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPruner(t *testing.T) {
+	var (
+		factory = func(c calc.Calculator) eval.Evaluator {
+			return eval.NewInterpreter(func(_ env.Map) calc.Calculator {
+				return c
+			})
+		}
+		row = &mockRow{
+			info: &mockInfo{
+				name: "mock",
+			},
+		}
+		errCalc = errors.New("calc")
+	)
+
+	for _, tc := range []*struct {
+		title string
+		calc  calc.Calculator
+		want  bool
+		err   error
+	}{
+		{
+			title: "prune",
+			calc:  &mockCalculator{value: data.FromBool(true)},
+			want:  true,
+		},
+		{
+			title: "dig",
+			calc:  &mockCalculator{value: data.FromBool(false)},
+		},
+		{
+			title: "invalid expr type",
+			calc:  &mockCalculator{value: data.FromInt(1)},
+			err:   eval.ErrNotBoolExpr,
+		},
+		{
+			title: "calc error",
+			calc:  &mockCalculator{err: errCalc},
+			err:   errCalc,
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			got, err := eval.NewPruner(factory(tc.calc), env.New(), nil).Prune(row)
+			if tc.err != nil {
+				assert.ErrorIs(t, err, tc.err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
@@ -0,0 +1,187 @@
+package eval
+
+import (
+	"strings"
+
+	"github.com/berquerant/dql/ast"
+	"github.com/berquerant/dql/data"
+	"github.com/berquerant/dql/env"
+)
+
+// ExtractPrefixMonotone returns the conjuncts of expr that are monotone in path prefix,
+// combined by and.
+// Monotone in path prefix means that if the conjunct is false for a directory,
+// it is false for all descendants of the directory.
+// Returns nil if no such conjunct.
+//
+// The conjuncts below are recognized:
+//
+//	depth(name) < n, depth(name) <= n, n > depth(name), n >= depth(name)
+//	depth_from_root < n, depth_from_root <= n, n > depth_from_root, n >= depth_from_root
+//	name not like "pattern" if pattern is not anchored at the end by $ or \z
+//	not is_hidden
+//	and, or of them
+func ExtractPrefixMonotone(expr ast.Expr) ast.Expr {
+	if x, ok := unwrapExpr(expr).(*ast.AndExpr); ok {
+		var (
+			left  = ExtractPrefixMonotone(x.Left)
+			right = ExtractPrefixMonotone(x.Right)
+		)
+		switch {
+		case left == nil:
+			return right
+		case right == nil:
+			return left
+		default:
+			return &ast.AndExpr{
+				Left:  left,
+				Right: right,
+			}
+		}
+	}
+	if isPrefixMonotone(expr) {
+		return expr
+	}
+	return nil
+}
+
+func isPrefixMonotone(expr ast.Expr) bool {
+	switch x := unwrapExpr(expr).(type) {
+	case *ast.AndExpr:
+		return isPrefixMonotone(x.Left) && isPrefixMonotone(x.Right)
+	case *ast.OrExpr:
+		return isPrefixMonotone(x.Left) && isPrefixMonotone(x.Right)
+	case *ast.NotExpr:
+		// a descendant of a hidden directory is hidden
+		return isIdent(unwrapSimpleExpr(x.Expr), "is_hidden")
+	case *ast.BoolPrimaryComparison:
+		var (
+			left  = unwrapSimpleExpr(x.Left)
+			right = unwrapSimpleExpr(x.Right)
+		)
+		switch x.Op {
+		case ast.CmpLessThan, ast.CmpLessEqual:
+			return isDepthExpr(left) && isNumberLit(right)
+		case ast.CmpGreaterThan, ast.CmpGreaterEqual:
+			return isNumberLit(left) && isDepthExpr(right)
+		default:
+			return false
+		}
+	case *ast.PredicateLike:
+		if !x.IsNot || !isIdent(unwrapSimpleExpr(x.Target), "name") {
+			return false
+		}
+		p, ok := x.Pattern.(*ast.SimpleExprLit)
+		if !ok {
+			return false
+		}
+		s, ok := p.Lit.(*ast.StringLit)
+		// the pattern matched with a directory is also matched with the descendants
+		// unless it is anchored at the end
+		return ok && !strings.Contains(s.Value, "$") && !strings.Contains(s.Value, `\z`)
+	default:
+		return false
+	}
+}
+
+// unwrapExpr removes the wrappers and the parentheses of the expr.
+func unwrapExpr(expr ast.Expr) ast.Expr {
+	switch x := expr.(type) {
+	case *ast.BoolPrimaryPredicate:
+		return unwrapExpr(x.Pred)
+	case *ast.PredicateBitExpr:
+		return unwrapExpr(x.Expr)
+	case *ast.BitExprSimpleExpr:
+		return unwrapExpr(x.Expr)
+	case *ast.SimpleExprExpr:
+		return unwrapExpr(x.Expr)
+	default:
+		return expr
+	}
+}
+
+// unwrapSimpleExpr returns the simple expr wrapped by expr.
+// Returns nil if expr is not a simple expr.
+func unwrapSimpleExpr(expr ast.Expr) ast.SimpleExpr {
+	x, _ := unwrapExpr(expr).(ast.SimpleExpr)
+	return x
+}
+
+func isIdent(expr ast.SimpleExpr, name string) bool {
+	x, ok := expr.(*ast.Ident)
+	return ok && x.Value == name
+}
+
+func isDepthExpr(expr ast.SimpleExpr) bool {
+	if isIdent(expr, "depth_from_root") {
+		return true
+	}
+	f, ok := expr.(*ast.FunctionCall)
+	if !ok || strings.ToLower(f.FunctionName.Value) != "depth" {
+		return false
+	}
+	return f.Arguments != nil && len(f.Arguments.Exprs) == 1 && isIdent(unwrapSimpleExpr(f.Arguments.Exprs[0]), "name")
+}
+
+func isNumberLit(expr ast.SimpleExpr) bool {
+	switch x := expr.(type) {
+	case *ast.SimpleExprLit:
+		switch x.Lit.(type) {
+		case *ast.IntLit, *ast.FloatLit:
+			return true
+		default:
+			return false
+		}
+	case *ast.SimpleExprPrefixOp:
+		switch x.Op {
+		case ast.PreOpPlus, ast.PreOpMinus:
+			return isNumberLit(x.Expr)
+		default:
+			return false
+		}
+	default:
+		return false
+	}
+}
+
+// NewPushdownPruner returns a new Pruner that prunes the directory if expr is false.
+// expr should be monotone in path prefix, see ExtractPrefixMonotone.
+// Nothing is pruned when the evaluation fails, leaving the error to the where stage.
+func NewPushdownPruner(evaluator Evaluator, table env.Map, expr ast.Expr) Pruner {
+	return &pushdownPruner{
+		expr: evaluator.Prepare(table, expr),
+	}
+}
+
+type pushdownPruner struct {
+	expr Evaluation
+}
+
+func (s *pushdownPruner) Prune(row Row) (bool, error) {
+	r, err := s.expr.Row(row)
+	if err != nil || r.Type() != data.TypeBool {
+		return false, nil
+	}
+	return !r.Bool(), nil
+}
+
+// NewPruners returns a new Pruner that prunes the directory if any of pruners prunes it.
+func NewPruners(pruners ...Pruner) Pruner {
+	return &multiPruner{
+		pruners: pruners,
+	}
+}
+
+type multiPruner struct {
+	pruners []Pruner
+}
+
+func (s *multiPruner) Prune(row Row) (bool, error) {
+	for _, p := range s.pruners {
+		pruned, err := p.Prune(row)
+		if err != nil || pruned {
+			return pruned, err
+		}
+	}
+	return false, nil
+}
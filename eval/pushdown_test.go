@@ -0,0 +1,107 @@
+package eval_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/berquerant/dql/ast"
+	"github.com/berquerant/dql/calc"
+	"github.com/berquerant/dql/cc"
+	"github.com/berquerant/dql/data"
+	"github.com/berquerant/dql/env"
+	"github.com/berquerant/dql/errors"
+	"github.com/berquerant/dql/eval"
+	"github.com/stretchr/testify/assert"
+)
+
+func parseWhere(t *testing.T, cond string) ast.Expr {
+	lexer := cc.NewLexer(strings.NewReader("select name where " + cond + ";"))
+	if status := cc.Parse(lexer); status != 0 {
+		t.Fatalf("failed to parse %s", cond)
+	}
+	return lexer.Result().(*ast.Statement).WhereSection.Condition.Expr
+}
+
+func TestExtractPrefixMonotone(t *testing.T) {
+	for _, tc := range []*struct {
+		cond string
+		want string // empty if nothing extracted
+	}{
+		{cond: "depth(name) <= 3", want: "depth(name) <= 3"},
+		{cond: "depth(name) < -1", want: "depth(name) < -1"},
+		{cond: "3 > depth_from_root", want: "3 > depth_from_root"},
+		{cond: "depth_from_root <= 1.5", want: "depth_from_root <= 1.5"},
+		{cond: `ext(name) = ".go" and depth(name) <= 3`, want: "depth(name) <= 3"},
+		{cond: `depth(name) <= 3 and size > 0 and name not like "/vendor/"`, want: `depth(name) <= 3 and name not like "/vendor/"`},
+		{cond: `(depth(name) < 2 or name not like "vendor") and is_dir`, want: `depth(name) < 2 or name not like "vendor"`},
+		{cond: "not is_hidden and size > 0", want: "not is_hidden"},
+		{cond: "is_hidden"},
+		{cond: "not is_dir"},
+		{cond: "depth(name) >= 3"},
+		{cond: "depth(rel_name) <= 3"},
+		{cond: "depth_from_root <= size"},
+		{cond: "depth(name) <= 2 or size > 0"},
+		{cond: `not (is_dir and base(name) = ".git")`},
+		{cond: `name like "vendor"`},
+		{cond: `name not like "\.go$"`},
+		{cond: `rel_name not like "vendor"`},
+	} {
+		tc := tc
+		t.Run(tc.cond, func(t *testing.T) {
+			got := eval.ExtractPrefixMonotone(parseWhere(t, tc.cond))
+			if tc.want == "" {
+				assert.Nil(t, got)
+				return
+			}
+			if assert.NotNil(t, got) {
+				assert.Equal(t, tc.want, got.String())
+			}
+		})
+	}
+}
+
+func TestPushdownPruner(t *testing.T) {
+	var (
+		factory = func(c calc.Calculator) eval.Evaluator {
+			return eval.NewInterpreter(func(_ env.Map) calc.Calculator {
+				return c
+			})
+		}
+		row = &mockRow{
+			info: &mockInfo{
+				name: "mock",
+			},
+		}
+	)
+
+	for _, tc := range []*struct {
+		title string
+		calc  calc.Calculator
+		want  bool
+	}{
+		{
+			title: "prune",
+			calc:  &mockCalculator{value: data.FromBool(false)},
+			want:  true,
+		},
+		{
+			title: "dig",
+			calc:  &mockCalculator{value: data.FromBool(true)},
+		},
+		{
+			title: "not bool",
+			calc:  &mockCalculator{value: data.FromInt(0)},
+		},
+		{
+			title: "calc error",
+			calc:  &mockCalculator{err: errors.New("calc")},
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			got, err := eval.NewPushdownPruner(factory(tc.calc), env.New(), nil).Prune(row)
+			assert.Nil(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
@@ -2,11 +2,15 @@ package eval
 
 import (
 	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
 
 	"github.com/berquerant/dql/data"
 	"github.com/berquerant/dql/dig"
 	"github.com/berquerant/dql/env"
 	"github.com/berquerant/dql/errors"
+	"github.com/berquerant/dql/fstat"
 )
 
 /* source rows */
@@ -23,6 +27,28 @@ type (
 		Mode() string
 		ModTime() int
 		IsDir() bool
+		Root() string
+		RelName() string
+		DepthFromRoot() int
+		Inode() int
+		Dev() int
+		Nlink() int
+		UID() int
+		GID() int
+		Owner() string
+		Group() string
+		Atime() int
+		Ctime() int
+		Blocks() int
+		DiskUsage() int
+		IsSymlink() bool
+		LinkTarget() string
+		IsBrokenLink() bool
+		Type() string
+		Perm() string
+		ModeBits() int
+		IsIgnored() bool
+		IsHidden() bool
 		ToMap() map[string]data.Data
 	}
 )
@@ -54,38 +80,123 @@ func (s *errRow) MarshalJSON() ([]byte, error) {
 	})
 }
 
+// info reads the columns from the file info on demand.
+// The columns read are memoized.
+//
+// info is not safe for concurrent use, the memo and the stats are not synchronized.
+// A row is read by one goroutine at a time: the stages pass a row to the next stage through a channel,
+// and runOrdered passes an item to one worker only.
+// A stage that reads a row from multiple goroutines at once should copy or synchronize it.
 type info struct {
-	name    string
-	size    int
-	mode    string
-	modTime int
-	isDir   bool
+	v dig.FileInfo
+	// values are the columns read, indexed by the slots of columns
+	values []data.Data
+	// stat is the extended stats, loaded on demand
+	stat       fstat.Stat
+	hasStat    bool
+	statLoaded bool
 }
 
 func NewInfo(v dig.FileInfo) Info {
 	return &info{
-		name:    v.Name(),
-		size:    int(v.Size()),
-		mode:    v.Mode().String(),
-		modTime: int(v.ModTime().Unix()),
-		isDir:   v.IsDir(),
+		v: v,
 	}
 }
 
-func (s *info) Name() string { return s.name }
-func (s *info) Size() int    { return s.size }
-func (s *info) Mode() string { return s.mode }
-func (s *info) ModTime() int { return s.modTime }
-func (s *info) IsDir() bool  { return s.isDir }
-func (s *info) ToMap() map[string]data.Data {
-	return map[string]data.Data{
-		"name":     data.FromString(s.name),
-		"size":     data.FromInt(s.size),
-		"mode":     data.FromString(s.mode),
-		"mod_time": data.FromInt(s.modTime),
-		"is_dir":   data.FromBool(s.isDir),
+// column returns the value of the column, reads it on the first access.
+func (s *info) column(slot int) data.Data {
+	if s.values == nil {
+		s.values = make([]data.Data, len(columns))
 	}
+	if v := s.values[slot]; v != nil {
+		return v
+	}
+	v := columns[slot].get(s)
+	s.values[slot] = v
+	return v
+}
+
+// fstat loads the extended stats on the first call.
+func (s *info) fstat() {
+	if s.statLoaded {
+		return
+	}
+	s.statLoaded = true
+	if x, ok := fstat.FromSys(s.v.Sys()); ok {
+		s.stat = *x
+		s.hasStat = true
+	}
+}
+
+// isHiddenPath returns true if any element of the relative path starts with a dot.
+func isHiddenPath(relName string) bool {
+	for _, x := range strings.Split(filepath.ToSlash(relName), "/") {
+		if x != "." && x != ".." && strings.HasPrefix(x, ".") {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *info) Name() string       { return s.v.Name() }
+func (s *info) Size() int          { return int(s.v.Size()) }
+func (s *info) Mode() string       { return s.v.Mode().String() }
+func (s *info) ModTime() int       { return int(s.v.ModTime().Unix()) }
+func (s *info) IsDir() bool        { return s.v.IsDir() }
+func (s *info) Root() string       { return s.v.Root() }
+func (s *info) RelName() string    { return s.v.RelName() }
+func (s *info) IsSymlink() bool    { return s.v.IsSymlink() }
+func (s *info) LinkTarget() string { return s.v.LinkTarget() }
+func (s *info) IsBrokenLink() bool { return s.v.IsBrokenLink() }
+func (s *info) Type() string       { return fstat.FileType(s.v.Type()) }
+func (s *info) Perm() string       { return fmt.Sprintf("%04o", s.ModeBits()) }
+func (s *info) ModeBits() int      { return fstat.PermBits(s.v.Mode()) }
+func (s *info) IsIgnored() bool    { return s.v.IsIgnored() }
+func (s *info) IsHidden() bool     { return isHiddenPath(s.v.RelName()) }
+func (s *info) DepthFromRoot() int {
+	relName := s.v.RelName()
+	if relName == "." {
+		return 0
+	}
+	return strings.Count(filepath.ToSlash(relName), "/") + 1
 }
+
+// Extended stats are zero values if not available on the platform.
+
+func (s *info) Inode() int     { s.fstat(); return int(s.stat.Inode) }
+func (s *info) Dev() int       { s.fstat(); return int(s.stat.Dev) }
+func (s *info) Nlink() int     { s.fstat(); return int(s.stat.Nlink) }
+func (s *info) UID() int       { s.fstat(); return int(s.stat.UID) }
+func (s *info) GID() int       { s.fstat(); return int(s.stat.GID) }
+func (s *info) Blocks() int    { s.fstat(); return int(s.stat.Blocks) }
+func (s *info) DiskUsage() int { return s.Blocks() * fstat.BlockSize }
+func (s *info) Atime() int {
+	if s.fstat(); !s.hasStat {
+		return 0
+	}
+	return int(s.stat.Atime.Unix())
+}
+func (s *info) Ctime() int {
+	if s.fstat(); !s.hasStat {
+		return 0
+	}
+	return int(s.stat.Ctime.Unix())
+}
+func (s *info) Owner() string {
+	if s.fstat(); !s.hasStat {
+		return ""
+	}
+	return fstat.UserName(s.stat.UID)
+}
+func (s *info) Group() string {
+	if s.fstat(); !s.hasStat {
+		return ""
+	}
+	return fstat.GroupName(s.stat.GID)
+}
+
+func (s *info) ToMap() map[string]data.Data { return infoToMap(s) }
+
 func (s *info) MarshalJSON() ([]byte, error) {
 	return json.Marshal(s.ToMap())
 }
@@ -9,30 +9,104 @@ import (
 	"github.com/berquerant/dql/dig"
 	"github.com/berquerant/dql/env"
 	"github.com/berquerant/dql/errors"
+	"github.com/berquerant/dql/function"
 	"github.com/berquerant/dql/logger"
 	"github.com/berquerant/dql/preprocessor"
 )
 
 const (
-	AllSelectSymbol = "all"
+	AllSelectSymbol  = "all"
+	StatSelectSymbol = "stat"
+	RootColumn       = "root"
 )
 
+// StatColumns are the columns selected by StatSelectSymbol.
+var StatColumns = []string{
+	"inode",
+	"dev",
+	"nlink",
+	"uid",
+	"gid",
+	"owner",
+	"group_name",
+	"atime",
+	"ctime",
+	"blocks",
+	"disk_usage",
+}
+
 type (
 	Runner interface {
+		// Run digs names and returns the result rows.
+		// The caller should read the rows until the channel is closed,
+		// or the stages are blocked on sending forever.
 		Run(ctx context.Context, names ...string) <-chan SRow
+		// Open digs names and returns the cursor of the result rows.
+		// The stages are stopped by closing the cursor.
+		Open(ctx context.Context, names ...string) Cursor
 		Headers() []string
+		// Schema returns the columns of the result rows, inferred statically.
+		Schema() []ColumnSchema
 	}
 
 	runner struct {
-		stmt *ast.Statement
+		stmt   *ast.Statement
+		digger dig.Digger
+		spill  SpillConfig
+		// workers is the number of goroutines to evaluate where and select
+		workers int
+		// functions are the additional functions
+		functions []function.Function
 	}
 )
 
-func NewRunner(stmt *ast.Statement) Runner {
+// RunnerOption is an option of Runner.
+type RunnerOption func(*runner)
+
+// WithMemoryBudget makes Runner write the rows to sort or group to temporary files
+// when they exceed approximately n bytes.
+// No limit if n is not positive.
+func WithMemoryBudget(n int) RunnerOption {
+	return func(s *runner) {
+		s.spill.MemoryBudget = n
+	}
+}
+
+// WithTempDir sets the directory to create temporary files, os.TempDir by default.
+func WithTempDir(dir string) RunnerOption {
+	return func(s *runner) {
+		s.spill.TempDir = dir
+	}
+}
+
+// WithWorkers makes Runner evaluate the rows of where and select with n goroutines concurrently.
+// The order of the rows is preserved.
+func WithWorkers(n int) RunnerOption {
+	return func(s *runner) {
+		s.workers = n
+	}
+}
+
+// WithFunctions adds the functions available in the query.
+// They override the builtin functions of the same names, are not aggregations,
+// and should be safe for concurrent use with WithWorkers.
+func WithFunctions(functions ...function.Function) RunnerOption {
+	return func(s *runner) {
+		s.functions = append(s.functions, functions...)
+	}
+}
+
+func NewRunner(stmt *ast.Statement, digger dig.Digger, opt ...RunnerOption) Runner {
 	r := &runner{
-		stmt: stmt,
+		stmt:   stmt,
+		digger: digger,
+	}
+	for _, o := range opt {
+		o(r)
 	}
 	r.init()
+	// the rows to spill are not stat'ed if the statement needs no stats
+	r.spill.SkipStat = !NeedsStat(stmt)
 	return r
 }
 
@@ -43,16 +117,29 @@ func (s *runner) init() {
 }
 
 func (s *runner) Run(ctx context.Context, names ...string) <-chan SRow {
+	// sourceCtx is canceled by limit to stop digging when the rows are enough.
+	// The error row caused by the cancellation is discarded by limit.
+	sourceCtx, stopSource := ctx, func() {}
+	if s.stmt.LimitSection != nil {
+		var cancel context.CancelFunc
+		sourceCtx, cancel = context.WithCancel(ctx)
+		stopSource = cancel
+	}
 	var (
 		table   = s.prepareEnv()
 		where   = func(sourceC <-chan Row) <-chan Row { return s.where(ctx, table, sourceC) }
 		groupBy = func(sourceC <-chan Row) <-chan GRow { return s.groupBy(ctx, table, sourceC) }
 		having  = func(sourceC <-chan GRow) <-chan GRow { return s.having(ctx, table, sourceC) }
 		orderBy = func(sourceC <-chan GRow) <-chan GRow { return s.orderBy(ctx, table, sourceC) }
-		limit   = func(sourceC <-chan GRow) <-chan GRow { return s.limit(ctx, sourceC) }
+		limit   = func(sourceC <-chan GRow) <-chan GRow { return s.limit(ctx, stopSource, sourceC) }
 		selekt  = func(sourceC <-chan GRow) <-chan SRow { return s.selekt(ctx, table, sourceC) }
 	)
-	return selekt(limit(orderBy(having(groupBy(where(NewSource(dig.New()).Yield(ctx, names...)))))))
+	return selekt(limit(orderBy(having(groupBy(where(NewSource(s.digger, s.pruner(table)).Yield(sourceCtx, names...)))))))
+}
+
+func (s *runner) Open(ctx context.Context, names ...string) Cursor {
+	ctx, cancel := context.WithCancel(ctx)
+	return NewCursor(s.Run(ctx, names...), cancel)
 }
 
 func (s *runner) Headers() []string {
@@ -67,28 +154,73 @@ func (s *runner) Headers() []string {
 	return b.Get()
 }
 
-func (s *runner) preprocess() error {
+func (s *runner) Schema() []ColumnSchema {
+	functions := make([]string, len(s.functions))
+	for i, f := range s.functions {
+		functions[i] = f.Name()
+	}
+	var (
+		headers   = s.Headers()
+		inference = newTypeInference(s.prepareEnv(), functions)
+		schema    = make([]ColumnSchema, len(headers))
+	)
+	for i, t := range s.stmt.SelectSection.Terms.Terms {
+		x := inference.infer(t.Target.Expr)
+		schema[i] = ColumnSchema{
+			Name:     headers[i],
+			Types:    x.types.Types(),
+			Nullable: x.nullable,
+			Expr:     t.Target.Expr.String(),
+		}
+	}
+	return schema
+}
+
+func (s *runner) preprocess() error { return Preprocess(s.stmt) }
+
+// Preprocess translates the statement before evaluation,
+// e.g. expands the select symbols and completes the default arguments.
+// NewRunner also calls this, and preprocessing twice is harmless.
+func Preprocess(stmt *ast.Statement) error {
 	ps := []preprocessor.PreProcessor{
 		preprocessor.NewSelectAll(AllSelectSymbol),
+		preprocessor.NewSelectGroup(StatSelectSymbol, StatColumns...),
+		preprocessor.NewDefaultArgument("relpath", 1, RootColumn),
 	}
 	for _, p := range ps {
-		if err := p.PreProcess(s.stmt); err != nil {
+		if err := p.PreProcess(stmt); err != nil {
 			return errors.Wrap(err, "runner")
 		}
 	}
 	return nil
 }
 
+// normal returns the evaluator for the rows before grouping.
+func (s *runner) normal() Evaluator {
+	if len(s.functions) == 0 {
+		return NewCompiledEvaluator(calc.NewNormalCompiler)
+	}
+	return NewCompiledEvaluator(calc.NewNormalCompilerWith(s.functions...))
+}
+
+// aggregation returns the evaluator for the rows after grouping.
+func (s *runner) aggregation() Evaluator {
+	if len(s.functions) == 0 {
+		return NewCompiledEvaluator(calc.NewAggregationCompiler)
+	}
+	return NewCompiledEvaluator(calc.NewAggregationCompilerWith(s.functions...))
+}
+
 func (s *runner) selekt(ctx context.Context, table env.Map, sourceC <-chan GRow) <-chan SRow {
 	exprs := make([]ast.Expr, len(s.stmt.SelectSection.Terms.Terms))
 	for i, t := range s.stmt.SelectSection.Terms.Terms {
 		exprs[i] = t.Target.Expr
 	}
 	// TODO: implement distinct
-	return NewSelect(calc.NewAggregation, exprs).Select(ctx, table, sourceC)
+	return NewParallelSelect(s.aggregation(), exprs, s.workers).Select(ctx, table, sourceC)
 }
 
-func (s *runner) limit(ctx context.Context, sourceC <-chan GRow) <-chan GRow {
+func (s *runner) limit(ctx context.Context, stop func(), sourceC <-chan GRow) <-chan GRow {
 	if s.stmt.LimitSection == nil {
 		return sourceC
 	}
@@ -99,7 +231,7 @@ func (s *runner) limit(ctx context.Context, sourceC <-chan GRow) <-chan GRow {
 	if s.stmt.LimitSection.Offset != nil {
 		offset = s.stmt.LimitSection.Offset.Value
 	}
-	return NewLimit().Limit(ctx, lim, offset, sourceC)
+	return NewLimit(stop).Limit(ctx, lim, offset, sourceC)
 }
 
 func (s *runner) orderBy(ctx context.Context, table env.Map, sourceC <-chan GRow) <-chan GRow {
@@ -108,30 +240,74 @@ func (s *runner) orderBy(ctx context.Context, table env.Map, sourceC <-chan GRow
 	}
 	// TODO: multiple orderby
 	t := s.stmt.OrderBySection.Terms.Terms[0]
-	return NewOrderBy(calc.NewAggregation).Sort(ctx, table, t.Expr, t.Option.IsDesc, sourceC)
+	if n, ok := s.topN(); ok {
+		// only the rows passing limit are required
+		return NewTopN(s.aggregation(), n).Sort(ctx, table, t.Expr, t.Option.IsDesc, sourceC)
+	}
+	return NewSpillOrderBy(s.aggregation(), s.spill).Sort(ctx, table, t.Expr, t.Option.IsDesc, sourceC)
+}
+
+// topN returns the number of the rows required by limit and offset.
+// Returns false if no limit or the limit is invalid.
+func (s *runner) topN() (int, bool) {
+	if s.stmt.LimitSection == nil {
+		return 0, false
+	}
+	var (
+		lim    = s.stmt.LimitSection.Limit.Value
+		offset = 0
+	)
+	if s.stmt.LimitSection.Offset != nil {
+		offset = s.stmt.LimitSection.Offset.Value
+	}
+	if lim < 1 || offset < 0 {
+		return 0, false
+	}
+	return lim + offset, true
 }
 
 func (s *runner) having(ctx context.Context, table env.Map, sourceC <-chan GRow) <-chan GRow {
 	if s.stmt.HavingSection == nil {
 		return sourceC
 	}
-	return NewHaving(calc.NewAggregation).Filter(ctx, table, s.stmt.HavingSection.Condition.Expr, sourceC)
+	return NewHaving(s.aggregation()).Filter(ctx, table, s.stmt.HavingSection.Condition.Expr, sourceC)
 }
 
 func (s *runner) groupBy(ctx context.Context, table env.Map, sourceC <-chan Row) <-chan GRow {
 	if s.stmt.GroupBySection == nil {
-		return NewGroupBy("").Group(ctx, table, sourceC)
+		return NewSpillGroupBy("", s.spill).Group(ctx, table, sourceC)
 	}
 	// TODO: multiple groupby
 	ident := s.stmt.GroupBySection.Terms.Terms[0].Expr.(*ast.BoolPrimaryPredicate).Pred.(*ast.PredicateBitExpr).Expr.(*ast.BitExprSimpleExpr).Expr.(*ast.Ident)
-	return NewGroupBy(ident.Value).Group(ctx, table, sourceC)
+	return NewSpillGroupBy(ident.Value, s.spill).Group(ctx, table, sourceC)
+}
+
+func (s *runner) pruner(table env.Map) Pruner {
+	var pruners []Pruner
+	if s.stmt.PruneSection != nil {
+		pruners = append(pruners, NewPruner(s.normal(), table, s.stmt.PruneSection.Condition.Expr))
+	}
+	if s.stmt.WhereSection != nil {
+		// the rows pruned by the pushdown are also filtered out by where
+		if expr := ExtractPrefixMonotone(s.stmt.WhereSection.Condition.Expr); expr != nil {
+			pruners = append(pruners, NewPushdownPruner(s.normal(), table, expr))
+		}
+	}
+	switch len(pruners) {
+	case 0:
+		return nil
+	case 1:
+		return pruners[0]
+	default:
+		return NewPruners(pruners...)
+	}
 }
 
 func (s *runner) where(ctx context.Context, table env.Map, sourceC <-chan Row) <-chan Row {
 	if s.stmt.WhereSection == nil {
 		return sourceC
 	}
-	return NewWhere(calc.NewNormal).Filter(ctx, table, s.stmt.WhereSection.Condition.Expr, sourceC)
+	return NewParallelWhere(s.normal(), s.workers).Filter(ctx, table, s.stmt.WhereSection.Condition.Expr, sourceC)
 }
 
 func (s *runner) prepareEnv() env.Map {
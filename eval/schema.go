@@ -0,0 +1,298 @@
+package eval
+
+import (
+	"github.com/berquerant/dql/ast"
+	"github.com/berquerant/dql/data"
+	"github.com/berquerant/dql/env"
+	"github.com/berquerant/dql/function"
+)
+
+// ColumnSchema describes a column of the result rows.
+type ColumnSchema struct {
+	// Name is the header of the column, the alias or the expression.
+	Name string `json:"name"`
+	// Types are the possible types of the values.
+	// An arithmetic result is an int if it is integral, so it may be an int or a float.
+	// Empty if unknown, e.g. the results of the functions added by WithFunctions.
+	Types []data.Type `json:"types"`
+	// Nullable is true if the values may be unavailable, then they are the zero values of the types,
+	// e.g. link_target of the files that are not symbolic links.
+	Nullable bool `json:"nullable"`
+	// Expr is the expression of the column.
+	Expr string `json:"expr"`
+}
+
+// Type returns the type of the values if it is the only possible type.
+func (s ColumnSchema) Type() (data.Type, bool) {
+	if len(s.Types) != 1 {
+		return 0, false
+	}
+	return s.Types[0], true
+}
+
+// bitsType is the type of the arguments of the bit operators, int or bits.
+const bitsType = function.TypeInt | function.TypeString
+
+// inferredType is the result of the type inference of an expr.
+type inferredType struct {
+	types    function.Type
+	nullable bool
+}
+
+// typeInference infers the types of the exprs statically.
+type typeInference struct {
+	// table has the aliases
+	table env.Map
+	// functions are the names of the additional functions, their types are unknown
+	functions map[string]bool
+	// aliases are the aliases being inferred, to stop on cyclic references
+	aliases map[string]bool
+	// report receives the type errors, can be nil.
+	// The errors in the aliases are not reported, they are reported where they are selected.
+	report func(expr ast.Expr, format string, v ...interface{})
+}
+
+func newTypeInference(table env.Map, functions []string) *typeInference {
+	fs := make(map[string]bool, len(functions))
+	for _, f := range functions {
+		fs[f] = true
+	}
+	return &typeInference{
+		table:     table,
+		functions: fs,
+		aliases:   map[string]bool{},
+	}
+}
+
+func (s *typeInference) infer(expr ast.Expr) inferredType {
+	switch expr := expr.(type) {
+	case *ast.OrExpr:
+		return s.operator(expr, function.TypeBool, function.TypeBool, expr.Left, expr.Right)
+	case *ast.AndExpr:
+		return s.operator(expr, function.TypeBool, function.TypeBool, expr.Left, expr.Right)
+	case *ast.XorExpr:
+		return s.operator(expr, function.TypeBool, function.TypeBool, expr.Left, expr.Right)
+	case *ast.NotExpr:
+		return s.operator(expr, function.TypeBool, function.TypeBool, expr.Expr)
+	case *ast.BoolPrimaryComparison:
+		return s.comparison(expr, expr.Left, expr.Right)
+	case *ast.BoolPrimaryPredicate:
+		return s.infer(expr.Pred)
+	case *ast.PredicateIn:
+		var list []ast.Expr
+		if expr.List != nil {
+			list = expr.List.Exprs
+		}
+		return s.comparison(expr, expr.Target, list...)
+	case *ast.PredicateBetween:
+		return s.comparison(expr, expr.Target, expr.Left, expr.Right)
+	case *ast.PredicateLike:
+		return s.operator(expr, function.TypeString, function.TypeBool, expr.Target, expr.Pattern)
+	case *ast.PredicateBitExpr:
+		return s.infer(expr.Expr)
+	case *ast.BitExprBitOp:
+		return s.operator(expr, bitsType, function.TypeInt, expr.Left, expr.Right)
+	case *ast.BitExprArtOp:
+		return s.arithmetic(expr)
+	case *ast.BitExprSimpleExpr:
+		return s.infer(expr.Expr)
+	case *ast.SimpleExprPrefixOp:
+		return s.prefixOp(expr)
+	case *ast.SimpleExprLit:
+		return inferredType{types: s.lit(expr.Lit)}
+	case *ast.Ident:
+		return s.ident(expr.Value)
+	case *ast.FunctionCall:
+		return s.functionCall(expr)
+	case *ast.SimpleExprExpr:
+		return s.infer(expr.Expr)
+	default:
+		return inferredType{}
+	}
+}
+
+func (s *typeInference) errorf(expr ast.Expr, format string, v ...interface{}) {
+	if s.report != nil {
+		s.report(expr, format, v...)
+	}
+}
+
+// check reports the arg if it is not of the type.
+func (s *typeInference) check(expr, arg ast.Expr, want function.Type, got inferredType) {
+	if got.types.IsDisjoint(want) {
+		s.errorf(expr, "%s want %s but got %s", arg, want, got.types)
+	}
+}
+
+// operator returns the result type of the operator, reports the args not of the argument type.
+func (s *typeInference) operator(expr ast.Expr, argType, resultType function.Type, args ...ast.Expr) inferredType {
+	r := inferredType{types: resultType}
+	for _, a := range args {
+		x := s.infer(a)
+		s.check(expr, a, argType, x)
+		r.nullable = r.nullable || x.nullable
+	}
+	return r
+}
+
+// comparison returns the bool type, reports the args not of the type of the target.
+func (s *typeInference) comparison(expr ast.Expr, target ast.Expr, args ...ast.Expr) inferredType {
+	x := s.infer(target)
+	r := inferredType{
+		types:    function.TypeBool,
+		nullable: x.nullable,
+	}
+	for _, a := range args {
+		y := s.infer(a)
+		if x.types.IsDisjoint(y.types) {
+			s.errorf(expr, "cannot compare %s and %s", x.types, y.types)
+		}
+		r.nullable = r.nullable || y.nullable
+	}
+	return r
+}
+
+func (s *typeInference) arithmetic(expr *ast.BitExprArtOp) inferredType {
+	var (
+		left  = s.infer(expr.Left)
+		right = s.infer(expr.Right)
+		r     = inferredType{
+			types:    function.TypeNumber,
+			nullable: left.nullable || right.nullable,
+		}
+	)
+	s.check(expr, expr.Left, function.TypeNumber, left)
+	s.check(expr, expr.Right, function.TypeNumber, right)
+	if expr.Op != ast.ArtOpDivide && left.types.IsSubsetOf(function.TypeInt) && right.types.IsSubsetOf(function.TypeInt) {
+		r.types = function.TypeInt
+	}
+	return r
+}
+
+func (s *typeInference) prefixOp(expr *ast.SimpleExprPrefixOp) inferredType {
+	x := s.infer(expr.Expr)
+	switch expr.Op {
+	case ast.PreOpPlus:
+		return x
+	case ast.PreOpMinus:
+		s.check(expr, expr.Expr, function.TypeNumber, x)
+		return inferredType{types: x.types & function.TypeNumber, nullable: x.nullable}
+	case ast.PreOpBitNot:
+		s.check(expr, expr.Expr, bitsType, x)
+		return inferredType{types: function.TypeInt, nullable: x.nullable}
+	case ast.PreOpNot:
+		s.check(expr, expr.Expr, function.TypeBool, x)
+		return inferredType{types: function.TypeBool, nullable: x.nullable}
+	default:
+		return inferredType{}
+	}
+}
+
+func (*typeInference) lit(expr ast.Lit) function.Type {
+	switch expr.(type) {
+	case *ast.IntLit:
+		return function.TypeInt
+	case *ast.FloatLit:
+		return function.TypeFloat
+	case *ast.StringLit:
+		return function.TypeString
+	default:
+		return function.TypeUnknown
+	}
+}
+
+// ident infers the column or the alias as the evaluators resolve them.
+func (s *typeInference) ident(name string) inferredType {
+	if slot, ok := columnSlots[name]; ok {
+		c := columns[slot]
+		return inferredType{
+			types:    function.TypeOf(c.typ),
+			nullable: c.nullable,
+		}
+	}
+	v, ok := s.table.Get(name)
+	if !ok || s.aliases[name] {
+		return inferredType{}
+	}
+	switch v.Type() {
+	case env.TypeData:
+		return inferredType{types: function.TypeOf(v.Data().Type())}
+	case env.TypeExpr:
+		s.aliases[name] = true
+		report := s.report
+		s.report = nil
+		defer func() {
+			delete(s.aliases, name)
+			s.report = report
+		}()
+		return s.infer(v.Expr())
+	default:
+		return inferredType{}
+	}
+}
+
+func (s *typeInference) functionCall(expr *ast.FunctionCall) inferredType {
+	var args []ast.Expr
+	if expr.Arguments != nil {
+		args = expr.Arguments.Exprs
+	}
+	var (
+		xs = make([]function.Arg, len(args))
+		r  inferredType
+	)
+	for i, a := range args {
+		x := s.infer(a)
+		xs[i].Type = x.types
+		if lit, ok := stringLit(a); ok {
+			xs[i].Lit = data.FromString(lit)
+		}
+		r.nullable = r.nullable || x.nullable
+	}
+	if s.functions[expr.FunctionName.Value] {
+		return r
+	}
+	sig, ok := function.LookupSignature(expr.FunctionName.Value)
+	if !ok {
+		return r
+	}
+	s.checkArgs(expr, sig, args, xs)
+	r.types = sig.Result(xs)
+	if sig.NonNullable {
+		r.nullable = false
+	}
+	return r
+}
+
+// checkArgs reports the args not matching the signature.
+func (s *typeInference) checkArgs(expr *ast.FunctionCall, sig *function.Signature, args []ast.Expr, xs []function.Arg) {
+	if !sig.AcceptsArity(len(args)) {
+		s.errorf(expr, "%s want %s args but got %d", expr.FunctionName.Value, sig.Arity(), len(args))
+		return
+	}
+	for i, x := range xs {
+		s.check(expr, args[i], sig.Param(i).Type, inferredType{types: x.Type})
+	}
+}
+
+// stringLit returns the string literal if expr is just a string literal.
+func stringLit(expr ast.Expr) (string, bool) {
+	for {
+		switch x := expr.(type) {
+		case *ast.BoolPrimaryPredicate:
+			expr = x.Pred
+		case *ast.PredicateBitExpr:
+			expr = x.Expr
+		case *ast.BitExprSimpleExpr:
+			expr = x.Expr
+		case *ast.SimpleExprExpr:
+			expr = x.Expr
+		case *ast.SimpleExprLit:
+			if lit, ok := x.Lit.(*ast.StringLit); ok {
+				return lit.Value, true
+			}
+			return "", false
+		default:
+			return "", false
+		}
+	}
+}
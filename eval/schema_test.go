@@ -0,0 +1,126 @@
+package eval_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/berquerant/dql/data"
+	"github.com/berquerant/dql/dig"
+	"github.com/berquerant/dql/eval"
+	"github.com/berquerant/dql/function"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchema(t *testing.T) {
+	var (
+		i = data.TypeInt
+		f = data.TypeFloat
+		s = data.TypeString
+		b = data.TypeBool
+	)
+
+	for _, tc := range []*struct {
+		title    string
+		expr     string
+		types    []data.Type
+		nullable bool
+	}{
+		{title: "column", expr: "size", types: []data.Type{i}},
+		{title: "nullable column", expr: "link_target", types: []data.Type{s}, nullable: true},
+		{title: "int literal", expr: "1", types: []data.Type{i}},
+		{title: "float literal", expr: "1.5", types: []data.Type{f}},
+		{title: "string literal", expr: `"x"`, types: []data.Type{s}},
+		{title: "int arithmetic", expr: "size * 2 + 1", types: []data.Type{i}},
+		{title: "division", expr: "size / 2", types: []data.Type{i, f}},
+		{title: "float arithmetic", expr: "size + 0.5", types: []data.Type{i, f}},
+		{title: "nullable arithmetic", expr: "inode + 1", types: []data.Type{i}, nullable: true},
+		{title: "bit", expr: "mode_bits & 7", types: []data.Type{i}},
+		{title: "minus", expr: "-size", types: []data.Type{i}},
+		{title: "not", expr: "not is_dir", types: []data.Type{b}},
+		{title: "comparison", expr: "size > 10", types: []data.Type{b}},
+		{title: "logical", expr: "is_dir or owner = \"x\"", types: []data.Type{b}, nullable: true},
+		{title: "in", expr: "size in (1, 2)", types: []data.Type{b}},
+		{title: "like", expr: `name like "x"`, types: []data.Type{b}},
+		{title: "between", expr: "size between 1 and 2", types: []data.Type{b}},
+		{title: "function", expr: "base(name)", types: []data.Type{s}},
+		{title: "nullable function", expr: "len(link_target)", types: []data.Type{i}, nullable: true},
+		{title: "cast", expr: `cast(size, "string")`, types: []data.Type{s}},
+		{title: "cast timestamp", expr: `cast(name, "timestamp")`, types: []data.Type{i}},
+		{title: "cast to unknown", expr: `cast(size, name)`, types: []data.Type{}},
+		{title: "floor", expr: "floor(size / 2)", types: []data.Type{i}},
+		{title: "pow", expr: "pow(size, 2)", types: []data.Type{i, f}},
+		{title: "count", expr: "count(link_target)", types: []data.Type{i}},
+		{title: "max", expr: "max(name)", types: []data.Type{s}},
+		{title: "sum of ints", expr: "sum(size)", types: []data.Type{i}},
+		{title: "sum of floats", expr: "sum(size / 2)", types: []data.Type{i, f}},
+		{title: "avg", expr: "avg(size)", types: []data.Type{i, f}},
+		{title: "alias", expr: "half + 1", types: []data.Type{i, f}},
+		{title: "unknown ident", expr: "unknown", types: []data.Type{}},
+		{title: "unknown function", expr: "unknown(size)", types: []data.Type{}},
+		{title: "custom function", expr: "base(size)", types: []data.Type{}},
+		{title: "cyclic alias", expr: "x", types: []data.Type{}},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			var opt []eval.RunnerOption
+			if tc.title == "custom function" {
+				opt = append(opt, eval.WithFunctions(function.NewFunction("base", nil)))
+			}
+			stmt := parseStatement(t, "select "+tc.expr+" as target, size / 2 as half, y as x, x as y;")
+			got := eval.NewRunner(stmt, dig.New(), opt...).Schema()[0]
+			assert.Equal(t, eval.ColumnSchema{
+				Name:     "target",
+				Types:    tc.types,
+				Nullable: tc.nullable,
+				Expr:     tc.expr,
+			}, got)
+		})
+	}
+}
+
+// The values of the rows should have the types of the schema.
+func TestSchemaTypes(t *testing.T) {
+	root := t.TempDir()
+	for _, f := range []string{"a.go", "b.txt", "d/c"} {
+		p := filepath.Join(root, f)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(f), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink("a.go", filepath.Join(root, "l")); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, query := range []string{
+		"select all, rel_name, type, perm, link_target, size / 3, size + 0.5, int2hex(size), glob_match(name, \"*.go\");",
+		"select stat;",
+		"select is_dir, count(name), sum(size), avg(size), max(name) group by is_dir;",
+		"select count(name), sum(size / 3), product(size), min(mod_time);",
+	} {
+		query := query
+		t.Run(query, func(t *testing.T) {
+			runner := eval.NewRunner(parseStatement(t, query), dig.New())
+			schema := runner.Schema()
+			cur := runner.Open(context.TODO(), root)
+			defer cur.Close()
+			var count int
+			for cur.Next() {
+				count++
+				row := cur.Row()
+				if !assert.Equal(t, len(schema), row.Len()) {
+					return
+				}
+				for i, c := range schema {
+					assert.Contains(t, c.Types, row.Get(i).Type(), "%s %v", c.Name, row.Get(i).Value())
+				}
+			}
+			assert.Nil(t, cur.Err())
+			assert.Less(t, 0, count)
+		})
+	}
+}
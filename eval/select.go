@@ -5,7 +5,6 @@ import (
 
 	"github.com/berquerant/dql/ast"
 	"github.com/berquerant/dql/async"
-	"github.com/berquerant/dql/calc"
 	"github.com/berquerant/dql/data"
 	"github.com/berquerant/dql/env"
 	"github.com/berquerant/dql/errors"
@@ -19,15 +18,26 @@ type (
 
 	selectImpl struct {
 		exprs         []ast.Expr
-		calcFactory   func(env.Map) calc.Calculator
+		evaluator     Evaluator
 		isAggregation bool
+		workers       int
 	}
 )
 
-func NewSelect(calcFactory func(env.Map) calc.Calculator, exprs []ast.Expr) Select {
+func NewSelect(evaluator Evaluator, exprs []ast.Expr) Select {
+	return NewParallelSelect(evaluator, exprs, 1)
+}
+
+// NewParallelSelect returns a new Select that evaluates the rows with the workers concurrently.
+// The order of the rows is preserved.
+func NewParallelSelect(evaluator Evaluator, exprs []ast.Expr, workers int) Select {
+	if workers < 1 {
+		workers = 1
+	}
 	x := &selectImpl{
-		exprs:       exprs,
-		calcFactory: calcFactory,
+		exprs:     exprs,
+		evaluator: evaluator,
+		workers:   workers,
 	}
 	x.isAggregation = x.containsAggregation()
 	return x
@@ -42,52 +52,56 @@ func (s *selectImpl) containsAggregation() bool {
 	return false
 }
 
+// selectState is the state of Select over the rows.
+type selectState struct {
+	isRawAggregation bool
+	rowCount         int
+	rawRows          []Row
+}
+
 func (s *selectImpl) Select(ctx context.Context, table env.Map, sourceC <-chan GRow) <-chan SRow {
 	resultC := make(chan SRow, resultCBufferSize)
+	ess := make([][]Evaluation, s.workers)
+	for i := range ess {
+		ess[i] = make([]Evaluation, len(s.exprs))
+		for j, expr := range s.exprs {
+			ess[i][j] = s.evaluator.Prepare(table, expr)
+		}
+	}
 	go func() {
 		defer close(resultC)
+		defer drainGRows(sourceC)
 
 		var (
-			isRawAggregation bool
-			rowCount         int
-			rawRows          = []Row{}
-		)
-		for r := range sourceC {
-			rowCount++
-			if async.IsDone(ctx) {
-				resultC <- NewErrSRow(errors.Wrap(ctx.Err(), "select"))
-				return
-			}
-			if s.isAggregation && r.Type() == RawRowType {
-				isRawAggregation = true
-				rawRows = append(rawRows, r.Raw())
-				continue
+			state = &selectState{
+				rawRows: []Row{},
 			}
-
-			values := make([]data.Data, len(s.exprs))
-			for i, expr := range s.exprs {
-				v, err := s.evalRow(table, expr, r)
-				if err != nil {
-					resultC <- NewErrSRow(errors.Wrap(err, "select"))
-					return
+			ok bool
+		)
+		if s.workers > 1 {
+			ok = s.selectParallel(ctx, state, ess, sourceC, resultC)
+		} else {
+			ok = true
+			for r := range sourceC {
+				r := r
+				if !s.emit(ctx, state, resultC, r, func() ([]data.Data, error) { return s.eval(ess[0], r) }) {
+					ok = false
+					break
 				}
-				values[i] = v
 			}
-			resultC <- NewSRow(values)
 		}
-
-		if !isRawAggregation {
+		if !ok || !state.isRawAggregation {
 			return
 		}
 		// aggregation below
-		if len(rawRows) != rowCount {
+		if len(state.rawRows) != state.rowCount {
 			resultC <- NewErrSRow(errors.Wrap(ErrInvalidSelectSource,
-				"select got %d rows but %d raw on aggregation", rowCount, len(rawRows)))
+				"select got %d rows but %d raw on aggregation", state.rowCount, len(state.rawRows)))
 			return
 		}
 		values := make([]data.Data, len(s.exprs))
-		for i, expr := range s.exprs {
-			v, err := s.evalAggregation(table, expr, rawRows)
+		for i, e := range ess[0] {
+			v, err := e.Rows(state.rawRows)
 			if err != nil {
 				resultC <- NewErrSRow(errors.Wrap(err, "select"))
 				return
@@ -99,16 +113,84 @@ func (s *selectImpl) Select(ctx context.Context, table env.Map, sourceC <-chan G
 	return resultC
 }
 
-func (s *selectImpl) evalAggregation(table env.Map, expr ast.Expr, rows []Row) (data.Data, error) {
-	return s.calcFactory(AppendRowsToEnv(table, rows)).Data(expr)
+func (s *selectImpl) selectParallel(
+	ctx context.Context, state *selectState, ess [][]Evaluation, sourceC <-chan GRow, resultC chan<- SRow,
+) bool {
+	type result struct {
+		row    GRow
+		values []data.Data
+		err    error
+	}
+	ok := true
+	runOrdered(
+		s.workers,
+		func() (interface{}, bool) {
+			r, ok := <-sourceC
+			return r, ok
+		},
+		func(worker int, item interface{}) interface{} {
+			r := item.(GRow)
+			if r.Err() != nil || s.isRawAggregationRow(r) {
+				return &result{row: r}
+			}
+			values, err := s.eval(ess[worker], r)
+			return &result{
+				row:    r,
+				values: values,
+				err:    err,
+			}
+		},
+		func(x interface{}) bool {
+			r := x.(*result)
+			ok = s.emit(ctx, state, resultC, r.row, func() ([]data.Data, error) { return r.values, r.err })
+			return ok
+		},
+	)
+	return ok
 }
 
-func (s *selectImpl) evalRow(table env.Map, expr ast.Expr, row GRow) (data.Data, error) {
-	t, err := AppendGRowToEnv(table, row)
+func (s *selectImpl) isRawAggregationRow(r GRow) bool {
+	return s.isAggregation && r.Type() == RawRowType
+}
+
+// emit sends the values of the row to resultC, or keeps the row to aggregate.
+// Returns false if the rows should not be read anymore.
+func (s *selectImpl) emit(
+	ctx context.Context, state *selectState, resultC chan<- SRow, r GRow, eval func() ([]data.Data, error),
+) bool {
+	state.rowCount++
+	if async.IsDone(ctx) {
+		resultC <- NewErrSRow(errors.Wrap(ctx.Err(), "select"))
+		return false
+	}
+	if err := r.Err(); err != nil {
+		resultC <- NewErrSRow(errors.Wrap(err, "select"))
+		return false
+	}
+	if s.isRawAggregationRow(r) {
+		state.isRawAggregation = true
+		state.rawRows = append(state.rawRows, r.Raw())
+		return true
+	}
+	values, err := eval()
 	if err != nil {
-		return nil, err
+		resultC <- NewErrSRow(errors.Wrap(err, "select"))
+		return false
+	}
+	resultC <- NewSRow(values)
+	return true
+}
+
+func (s *selectImpl) eval(es []Evaluation, r GRow) ([]data.Data, error) {
+	values := make([]data.Data, len(es))
+	for i, e := range es {
+		v, err := evalGRow(e, r)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = v
 	}
-	return s.calcFactory(t).Data(expr)
+	return values, nil
 }
 
 // DetectAggregation returns true if expr contains aggregation function call.
@@ -15,12 +15,17 @@ type (
 
 	source struct {
 		digger dig.Digger
+		pruner Pruner
 	}
 )
 
-func NewSource(digger dig.Digger) Source {
+// NewSource returns a new Source.
+// The directories pruned by pruner and their descendants are not yielded.
+// Nothing is pruned if pruner is nil.
+func NewSource(digger dig.Digger, pruner Pruner) Source {
 	return &source{
 		digger: digger,
+		pruner: pruner,
 	}
 }
 
@@ -28,18 +33,34 @@ func (s *source) Yield(ctx context.Context, names ...string) <-chan Row {
 	resultC := make(chan Row, resultCBufferSize)
 	go func() {
 		defer close(resultC)
+		var yieldErr error
 		for _, name := range names {
 			if err := s.digger.Dig(name, func(v dig.FileInfo) dig.Instr {
 				if async.IsDone(ctx) {
-					resultC <- NewErrRow(errors.Wrap(ctx.Err(), "yield"))
+					yieldErr = ctx.Err()
 					return dig.InstrCancel
 				}
-				resultC <- NewRow(NewInfo(v))
+				row := NewRow(NewInfo(v))
+				if s.pruner != nil && v.IsDir() {
+					pruned, err := s.pruner.Prune(row)
+					if err != nil {
+						yieldErr = err
+						return dig.InstrCancel
+					}
+					if pruned {
+						return dig.InstrSkipDir
+					}
+				}
+				resultC <- row
 				return dig.InstrContinue
 			}); err != nil {
 				resultC <- NewErrRow(errors.Wrap(err, "yield"))
 				return
 			}
+			if yieldErr != nil {
+				resultC <- NewErrRow(errors.Wrap(yieldErr, "yield"))
+				return
+			}
 		}
 	}()
 	return resultC
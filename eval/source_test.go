@@ -18,6 +18,8 @@ type mockFileInfo struct {
 	mode    fs.FileMode
 	modTime time.Time
 	isDir   bool
+	root    string
+	relName string
 }
 
 func (s *mockFileInfo) Name() string       { return s.name }
@@ -25,18 +27,32 @@ func (s *mockFileInfo) Size() int64        { return s.size }
 func (s *mockFileInfo) Mode() fs.FileMode  { return s.mode }
 func (s *mockFileInfo) ModTime() time.Time { return s.modTime }
 func (s *mockFileInfo) IsDir() bool        { return s.isDir }
+func (s *mockFileInfo) Type() fs.FileMode  { return s.mode.Type() }
+func (*mockFileInfo) Sys() interface{}     { return nil }
+func (*mockFileInfo) IsSymlink() bool      { return false }
+func (*mockFileInfo) LinkTarget() string   { return "" }
+func (*mockFileInfo) IsBrokenLink() bool   { return false }
+func (*mockFileInfo) IsIgnored() bool      { return false }
+func (s *mockFileInfo) Root() string       { return s.root }
+func (s *mockFileInfo) RelName() string    { return s.relName }
 
 type mockDigger struct {
-	infos []dig.FileInfo
-	err   error
+	infos  []dig.FileInfo
+	err    error
+	instrs []dig.Instr
 }
 
-func (s *mockDigger) Dig(_ string, handler dig.FileInfoHandler) error {
+func (s *mockDigger) Dig(name string, handler dig.FileInfoHandler) error {
 	if s.err != nil {
 		return s.err
 	}
 	for _, x := range s.infos {
-		if handler(x) == dig.InstrCancel {
+		if v, ok := x.(*mockFileInfo); ok {
+			v.root = name
+		}
+		instr := handler(x)
+		s.instrs = append(s.instrs, instr)
+		if instr == dig.InstrCancel {
 			break
 		}
 	}
@@ -66,7 +82,7 @@ func TestSource(t *testing.T) {
 		errSource := errors.New("error source")
 		got := resultToRows(eval.NewSource(&mockDigger{
 			err: errSource,
-		}).Yield(context.TODO(), ""))
+		}, nil).Yield(context.TODO(), ""))
 		assert.Equal(t, 1, len(got))
 		assert.ErrorIs(t, got[0].Err(), errSource)
 	})
@@ -76,7 +92,7 @@ func TestSource(t *testing.T) {
 		cancel()
 		got := resultToRows(eval.NewSource(&mockDigger{
 			infos: newFileInfos("a"),
-		}).Yield(ctx, ""))
+		}, nil).Yield(ctx, ""))
 		assert.Equal(t, 1, len(got))
 		assert.ErrorIs(t, got[0].Err(), context.Canceled)
 	})
@@ -84,9 +100,122 @@ func TestSource(t *testing.T) {
 	t.Run("yield", func(t *testing.T) {
 		got := resultToRows(eval.NewSource(&mockDigger{
 			infos: newFileInfos("a", "b"),
-		}).Yield(context.TODO(), ""))
+		}, nil).Yield(context.TODO(), "root"))
 		assert.Equal(t, 2, len(got))
 		assert.Equal(t, "a", got[0].Info().Name())
 		assert.Equal(t, "b", got[1].Info().Name())
+		assert.Equal(t, "root", got[0].Info().Root())
+	})
+
+	t.Run("prune", func(t *testing.T) {
+		digger := &mockDigger{
+			infos: []dig.FileInfo{
+				&mockFileInfo{name: "a", isDir: true},
+				&mockFileInfo{name: "b", isDir: true},
+				&mockFileInfo{name: "c"},
+			},
+		}
+		got := resultToRows(eval.NewSource(digger, &mockPruner{
+			names: []string{"b", "c"},
+		}).Yield(context.TODO(), "root"))
+		assert.Equal(t, 2, len(got))
+		assert.Equal(t, "a", got[0].Info().Name())
+		assert.Equal(t, "c", got[1].Info().Name())
+		assert.Equal(t, []dig.Instr{dig.InstrContinue, dig.InstrSkipDir, dig.InstrContinue}, digger.instrs)
+	})
+
+	t.Run("prune error", func(t *testing.T) {
+		errPrune := errors.New("error prune")
+		got := resultToRows(eval.NewSource(&mockDigger{
+			infos: []dig.FileInfo{
+				&mockFileInfo{name: "a", isDir: true},
+			},
+		}, &mockPruner{
+			err: errPrune,
+		}).Yield(context.TODO(), "root", "root2"))
+		assert.Equal(t, 1, len(got))
+		assert.ErrorIs(t, got[0].Err(), errPrune)
+	})
+}
+
+type mockPruner struct {
+	names []string
+	err   error
+}
+
+func (s *mockPruner) Prune(row eval.Row) (bool, error) {
+	if s.err != nil {
+		return false, s.err
+	}
+	for _, x := range s.names {
+		if x == row.Info().Name() {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func TestInfo(t *testing.T) {
+	for _, tc := range []*struct {
+		title   string
+		relName string
+		depth   int
+		hidden  bool
+	}{
+		{
+			title:   "root",
+			relName: ".",
+			depth:   0,
+		},
+		{
+			title:   "child",
+			relName: "a",
+			depth:   1,
+		},
+		{
+			title:   "grandchild",
+			relName: "a/b",
+			depth:   2,
+		},
+		{
+			title:   "hidden",
+			relName: "a/.b",
+			depth:   2,
+			hidden:  true,
+		},
+		{
+			title:   "child of hidden",
+			relName: ".a/b",
+			depth:   2,
+			hidden:  true,
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			got := eval.NewInfo(&mockFileInfo{
+				name:    "/r/" + tc.relName,
+				root:    "r",
+				relName: tc.relName,
+			})
+			assert.Equal(t, "r", got.Root())
+			assert.Equal(t, tc.relName, got.RelName())
+			assert.Equal(t, tc.depth, got.DepthFromRoot())
+			assert.Equal(t, tc.depth, got.ToMap()["depth_from_root"].Int())
+			assert.Equal(t, tc.hidden, got.IsHidden())
+		})
+	}
+}
+
+func TestInfoMode(t *testing.T) {
+	got := eval.NewInfo(&mockFileInfo{
+		name: "/r/bin",
+		mode: fs.ModeDir | fs.ModeSetgid | 0750,
 	})
+	assert.Equal(t, "dir", got.Type())
+	assert.Equal(t, "2750", got.Perm())
+	assert.Equal(t, 02750, got.ModeBits())
+	m := got.ToMap()
+	assert.Equal(t, "dir", m["type"].String())
+	assert.Equal(t, "2750", m["perm"].String())
+	assert.Equal(t, 02750, m["mode_bits"].Int())
 }
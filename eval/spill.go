@@ -0,0 +1,307 @@
+package eval
+
+import (
+	"bufio"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/berquerant/dql/data"
+	"github.com/berquerant/dql/errors"
+)
+
+// SpillConfig is the configuration to write rows to temporary files
+// instead of keeping them in memory.
+type SpillConfig struct {
+	// MemoryBudget is the approximate number of bytes of the rows kept in memory.
+	// The rows are never written to files if not positive.
+	MemoryBudget int
+	// TempDir is the directory to create temporary files.
+	// Uses os.TempDir if empty.
+	TempDir string
+	// SkipStat is true if the statement refers to none of the columns that require the stats, see NeedsStat.
+	// Then those columns are written as zero values, not to stat the files that are not stat'ed yet
+	// because of the lazy stat.
+	SkipStat bool
+}
+
+func (s SpillConfig) enabled() bool { return s.MemoryBudget > 0 }
+
+// mkdirTemp creates a new temporary directory for a spill.
+func (s SpillConfig) mkdirTemp() (string, error) {
+	dir, err := os.MkdirTemp(s.TempDir, "dql-spill-")
+	if err != nil {
+		return "", errors.Wrap(err, "spill")
+	}
+	return dir, nil
+}
+
+const (
+	// infoBaseSize is the approximate size of an info except for the strings.
+	infoBaseSize = 512
+	// spillPartitions is the number of the files to partition groups.
+	spillPartitions = 64
+)
+
+// estimateInfoSize returns the approximate number of bytes of the info in memory.
+func estimateInfoSize(v Info) int {
+	return infoBaseSize + len(v.Name()) + len(v.Root()) + len(v.RelName()) + len(v.LinkTarget())
+}
+
+// estimateGRowSize returns the approximate number of bytes of the row in memory.
+func estimateGRowSize(row GRow) int {
+	switch row.Type() {
+	case RawRowType:
+		return estimateInfoSize(row.Raw().Info())
+	case GroupedRowType:
+		var n int
+		for _, r := range row.Grouped().Rows() {
+			n += estimateInfoSize(r.Info())
+		}
+		return n
+	default:
+		return 0
+	}
+}
+
+// spilledInfo is the Info written to files.
+type spilledInfo struct {
+	Name          string
+	Size          int
+	Mode          string
+	ModTime       int
+	IsDir         bool
+	Root          string
+	RelName       string
+	DepthFromRoot int
+	Inode         int
+	Dev           int
+	Nlink         int
+	UID           int
+	GID           int
+	Owner         string
+	Group         string
+	Atime         int
+	Ctime         int
+	Blocks        int
+	DiskUsage     int
+	IsSymlink     bool
+	LinkTarget    string
+	IsBrokenLink  bool
+	Type          string
+	Perm          string
+	ModeBits      int
+	IsIgnored     bool
+	IsHidden      bool
+}
+
+// newSpilledInfo returns the columns of the info to write.
+// The columns that require the stats are zero values if skipStat.
+func newSpilledInfo(v Info, skipStat bool) *spilledInfo {
+	x := &spilledInfo{
+		Name:          v.Name(),
+		IsDir:         v.IsDir(),
+		Root:          v.Root(),
+		RelName:       v.RelName(),
+		DepthFromRoot: v.DepthFromRoot(),
+		IsSymlink:     v.IsSymlink(),
+		LinkTarget:    v.LinkTarget(),
+		IsBrokenLink:  v.IsBrokenLink(),
+		Type:          v.Type(),
+		IsIgnored:     v.IsIgnored(),
+		IsHidden:      v.IsHidden(),
+	}
+	if skipStat {
+		return x
+	}
+	x.Size = v.Size()
+	x.Mode = v.Mode()
+	x.ModTime = v.ModTime()
+	x.Inode = v.Inode()
+	x.Dev = v.Dev()
+	x.Nlink = v.Nlink()
+	x.UID = v.UID()
+	x.GID = v.GID()
+	x.Owner = v.Owner()
+	x.Group = v.Group()
+	x.Atime = v.Atime()
+	x.Ctime = v.Ctime()
+	x.Blocks = v.Blocks()
+	x.DiskUsage = v.DiskUsage()
+	x.Perm = v.Perm()
+	x.ModeBits = v.ModeBits()
+	return x
+}
+
+// restoredInfo is the Info read from files.
+type restoredInfo struct {
+	v *spilledInfo
+}
+
+func (s *restoredInfo) Name() string       { return s.v.Name }
+func (s *restoredInfo) Size() int          { return s.v.Size }
+func (s *restoredInfo) Mode() string       { return s.v.Mode }
+func (s *restoredInfo) ModTime() int       { return s.v.ModTime }
+func (s *restoredInfo) IsDir() bool        { return s.v.IsDir }
+func (s *restoredInfo) Root() string       { return s.v.Root }
+func (s *restoredInfo) RelName() string    { return s.v.RelName }
+func (s *restoredInfo) DepthFromRoot() int { return s.v.DepthFromRoot }
+func (s *restoredInfo) Inode() int         { return s.v.Inode }
+func (s *restoredInfo) Dev() int           { return s.v.Dev }
+func (s *restoredInfo) Nlink() int         { return s.v.Nlink }
+func (s *restoredInfo) UID() int           { return s.v.UID }
+func (s *restoredInfo) GID() int           { return s.v.GID }
+func (s *restoredInfo) Owner() string      { return s.v.Owner }
+func (s *restoredInfo) Group() string      { return s.v.Group }
+func (s *restoredInfo) Atime() int         { return s.v.Atime }
+func (s *restoredInfo) Ctime() int         { return s.v.Ctime }
+func (s *restoredInfo) Blocks() int        { return s.v.Blocks }
+func (s *restoredInfo) DiskUsage() int     { return s.v.DiskUsage }
+func (s *restoredInfo) IsSymlink() bool    { return s.v.IsSymlink }
+func (s *restoredInfo) LinkTarget() string { return s.v.LinkTarget }
+func (s *restoredInfo) IsBrokenLink() bool { return s.v.IsBrokenLink }
+func (s *restoredInfo) Type() string       { return s.v.Type }
+func (s *restoredInfo) Perm() string       { return s.v.Perm }
+func (s *restoredInfo) ModeBits() int      { return s.v.ModeBits }
+func (s *restoredInfo) IsIgnored() bool    { return s.v.IsIgnored }
+func (s *restoredInfo) IsHidden() bool     { return s.v.IsHidden }
+func (s *restoredInfo) ToMap() map[string]data.Data {
+	return infoToMap(s)
+}
+func (s *restoredInfo) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.ToMap())
+}
+
+// spilledGRow is the GRow written to files.
+type spilledGRow struct {
+	Type  RowType
+	Raw   *spilledInfo
+	Key   string
+	Value interface{}
+	Rows  []*spilledInfo
+}
+
+func newSpilledGRow(row GRow, skipStat bool) (*spilledGRow, error) {
+	switch row.Type() {
+	case RawRowType:
+		return &spilledGRow{
+			Type: RawRowType,
+			Raw:  newSpilledInfo(row.Raw().Info(), skipStat),
+		}, nil
+	case GroupedRowType:
+		g := row.Grouped()
+		rows := make([]*spilledInfo, len(g.Rows()))
+		for i, r := range g.Rows() {
+			rows[i] = newSpilledInfo(r.Info(), skipStat)
+		}
+		return &spilledGRow{
+			Type:  GroupedRowType,
+			Key:   g.Key(),
+			Value: g.Value().Value(),
+			Rows:  rows,
+		}, nil
+	default:
+		return nil, errors.Wrap(ErrUnknownRowType, "spill %s", row.Type())
+	}
+}
+
+func (s *spilledGRow) restore() (GRow, error) {
+	switch s.Type {
+	case RawRowType:
+		return NewRawGRow(NewRow(&restoredInfo{v: s.Raw})), nil
+	case GroupedRowType:
+		v, ok := data.FromInterface(s.Value)
+		if !ok {
+			return nil, errors.Wrap(ErrUnknownDataType, "restore %v", s.Value)
+		}
+		rows := make([]Row, len(s.Rows))
+		for i, r := range s.Rows {
+			rows[i] = NewRow(&restoredInfo{v: r})
+		}
+		return NewGroupedGRow(NewGroupedRow(s.Key, v, rows)), nil
+	default:
+		return nil, errors.Wrap(ErrUnknownRowType, "restore %s", s.Type)
+	}
+}
+
+// spillWriter writes the values to a file.
+type spillWriter struct {
+	f   *os.File
+	w   *bufio.Writer
+	enc *gob.Encoder
+}
+
+func newSpillWriter(name string) (*spillWriter, error) {
+	f, err := os.Create(name)
+	if err != nil {
+		return nil, errors.Wrap(err, "spill create %s", name)
+	}
+	w := bufio.NewWriter(f)
+	return &spillWriter{
+		f:   f,
+		w:   w,
+		enc: gob.NewEncoder(w),
+	}, nil
+}
+
+func (s *spillWriter) Write(v interface{}) error {
+	if err := s.enc.Encode(v); err != nil {
+		return errors.Wrap(err, "spill write %s", s.f.Name())
+	}
+	return nil
+}
+
+func (s *spillWriter) Close() error {
+	if err := s.w.Flush(); err != nil {
+		s.f.Close()
+		return errors.Wrap(err, "spill flush %s", s.f.Name())
+	}
+	return s.f.Close()
+}
+
+// spillReader reads the values written by spillWriter.
+type spillReader struct {
+	f   *os.File
+	dec *gob.Decoder
+}
+
+func newSpillReader(name string) (*spillReader, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, errors.Wrap(err, "spill open %s", name)
+	}
+	return &spillReader{
+		f:   f,
+		dec: gob.NewDecoder(bufio.NewReader(f)),
+	}, nil
+}
+
+// Read reads the next value into v.
+// Returns io.EOF if no more values.
+func (s *spillReader) Read(v interface{}) error {
+	if err := s.dec.Decode(v); err != nil {
+		if errors.Is(err, io.EOF) {
+			return io.EOF
+		}
+		return errors.Wrap(err, "spill read %s", s.f.Name())
+	}
+	return nil
+}
+
+func (s *spillReader) Close() error { return s.f.Close() }
+
+// spillFileName returns the name of the i-th file in dir.
+func spillFileName(dir string, i int) string {
+	return filepath.Join(dir, fmt.Sprintf("%06d", i))
+}
+
+// partitionOf returns the partition of the group key.
+func partitionOf(key interface{}) int {
+	h := fnv.New32a()
+	fmt.Fprintf(h, "%T:%v", key, key)
+	return int(h.Sum32() % spillPartitions)
+}
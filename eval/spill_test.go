@@ -0,0 +1,302 @@
+package eval_test
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/berquerant/dql/calc"
+	"github.com/berquerant/dql/data"
+	"github.com/berquerant/dql/env"
+	"github.com/berquerant/dql/eval"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSpillOrderBy(t *testing.T) {
+	var (
+		sizes = []int{3, 1, 3, 5, 1, 4, 3, 2, 5, 0}
+		rows  = func() []eval.GRow {
+			r := make([]eval.GRow, len(sizes))
+			for i, x := range sizes {
+				r[i] = eval.NewRawGRow(eval.NewRow(&mockInfo{
+					name: fmt.Sprint(i),
+					size: x,
+				}))
+			}
+			return r
+		}
+		groupedRows = func() []eval.GRow {
+			r := make([]eval.GRow, len(sizes))
+			for i, x := range sizes {
+				r[i] = eval.NewGroupedGRow(eval.NewGroupedRow("size", data.FromInt(x), []eval.Row{
+					eval.NewRow(&mockInfo{name: fmt.Sprint(i), size: x}),
+					eval.NewRow(&mockInfo{name: fmt.Sprint(i, "x"), size: x}),
+				}))
+			}
+			return r
+		}
+		factory = func() eval.Evaluator {
+			values := make([]data.Data, len(sizes))
+			for i, x := range sizes {
+				values[i] = data.FromInt(x)
+			}
+			c := &mockMultipleCalculator{
+				values: values,
+			}
+			return eval.NewInterpreter(func(_ env.Map) calc.Calculator {
+				return c
+			})
+		}
+		yield = func(rows []eval.GRow) <-chan eval.GRow {
+			c := make(chan eval.GRow, len(rows))
+			for _, r := range rows {
+				c <- r
+			}
+			close(c)
+			return c
+		}
+		names = func(rows []eval.GRow) []string {
+			r := make([]string, len(rows))
+			for i, x := range rows {
+				switch x.Type() {
+				case eval.RawRowType:
+					r[i] = fmt.Sprint(x.Raw().Info().Name(), x.Raw().Info().Size())
+				case eval.GroupedRowType:
+					g := x.Grouped()
+					r[i] = fmt.Sprint(g.Key(), g.Value().Value())
+					for _, y := range g.Rows() {
+						r[i] += fmt.Sprint(" ", y.Info().Name(), y.Info().Size())
+					}
+				default:
+					r[i] = x.Err().Error()
+				}
+			}
+			return r
+		}
+	)
+
+	for _, tc := range []*struct {
+		title string
+		rows  func() []eval.GRow
+	}{
+		{
+			title: "raw",
+			rows:  rows,
+		},
+		{
+			title: "grouped",
+			rows:  groupedRows,
+		},
+	} {
+		tc := tc
+		for _, isDesc := range []bool{false, true} {
+			want := names(resultToGRows(eval.NewOrderBy(factory()).Sort(context.TODO(), env.New(), nil, isDesc, yield(tc.rows()))))
+			for _, budget := range []int{1, 1024, 2048, 1 << 20} {
+				isDesc := isDesc
+				budget := budget
+				t.Run(fmt.Sprintf("%s desc %v budget %d", tc.title, isDesc, budget), func(t *testing.T) {
+					dir := t.TempDir()
+					got := names(resultToGRows(eval.NewSpillOrderBy(factory(), eval.SpillConfig{
+						MemoryBudget: budget,
+						TempDir:      dir,
+					}).Sort(context.TODO(), env.New(), nil, isDesc, yield(tc.rows()))))
+					assert.Equal(t, want, got)
+					assertEmptyDir(t, dir)
+				})
+			}
+		}
+	}
+}
+
+func TestSpillOrderByManyRuns(t *testing.T) {
+	// each row is a run because of the budget, more than the runs merged at once
+	const n = 300
+	var (
+		yield = func() <-chan eval.GRow {
+			c := make(chan eval.GRow, n)
+			for i := 0; i < n; i++ {
+				c <- eval.NewRawGRow(eval.NewRow(&mockInfo{
+					name: fmt.Sprint(i),
+					size: (i * 7) % 11,
+				}))
+			}
+			close(c)
+			return c
+		}
+		factory = func() eval.Evaluator {
+			values := make([]data.Data, n)
+			for i := 0; i < n; i++ {
+				values[i] = data.FromInt((i * 7) % 11)
+			}
+			c := &mockMultipleCalculator{
+				values: values,
+			}
+			return eval.NewInterpreter(func(_ env.Map) calc.Calculator {
+				return c
+			})
+		}
+		names = func(rows []eval.GRow) []string {
+			r := make([]string, len(rows))
+			for i, x := range rows {
+				if err := x.Err(); err != nil {
+					r[i] = err.Error()
+					continue
+				}
+				r[i] = fmt.Sprint(x.Raw().Info().Name(), " ", x.Raw().Info().Size())
+			}
+			return r
+		}
+	)
+
+	for _, isDesc := range []bool{false, true} {
+		isDesc := isDesc
+		t.Run(fmt.Sprintf("desc %v", isDesc), func(t *testing.T) {
+			want := names(resultToGRows(eval.NewOrderBy(factory()).Sort(context.TODO(), env.New(), nil, isDesc, yield())))
+			dir := t.TempDir()
+			got := names(resultToGRows(eval.NewSpillOrderBy(factory(), eval.SpillConfig{
+				MemoryBudget: 1,
+				TempDir:      dir,
+			}).Sort(context.TODO(), env.New(), nil, isDesc, yield())))
+			assert.Equal(t, n, len(got))
+			assert.Equal(t, want, got)
+			assertEmptyDir(t, dir)
+		})
+	}
+}
+
+func TestSpillGroupBy(t *testing.T) {
+	var (
+		sizes = []int{3, 1, 3, 5, 1, 4, 3, 2, 5, 0}
+		yield = func() <-chan eval.Row {
+			c := make(chan eval.Row, len(sizes))
+			for i, x := range sizes {
+				c <- eval.NewRow(&mockInfo{
+					name: fmt.Sprint(i),
+					size: x,
+				})
+			}
+			close(c)
+			return c
+		}
+		groups = func(rows []eval.GRow) []string {
+			r := make([]string, len(rows))
+			for i, x := range rows {
+				if err := x.Err(); err != nil {
+					r[i] = err.Error()
+					continue
+				}
+				g := x.Grouped()
+				r[i] = fmt.Sprint(g.Key(), g.Value().Value())
+				for _, y := range g.Rows() {
+					r[i] += fmt.Sprint(" ", y.Info().Name(), y.Info().Size())
+				}
+			}
+			// the order of the groups is not defined
+			sort.Strings(r)
+			return r
+		}
+	)
+
+	want := groups(resultToGRows(eval.NewGroupBy("size").Group(context.TODO(), env.New(), yield())))
+	for _, budget := range []int{1, 2048, 1 << 20} {
+		budget := budget
+		t.Run(fmt.Sprintf("budget %d", budget), func(t *testing.T) {
+			dir := t.TempDir()
+			got := groups(resultToGRows(eval.NewSpillGroupBy("size", eval.SpillConfig{
+				MemoryBudget: budget,
+				TempDir:      dir,
+			}).Group(context.TODO(), env.New(), yield())))
+			assert.Equal(t, want, got)
+			assertEmptyDir(t, dir)
+		})
+	}
+}
+
+func assertEmptyDir(t *testing.T, dir string) {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(entries), "temporary files are removed")
+}
+
+// mockStatFileInfo counts the calls to read the stat.
+type mockStatFileInfo struct {
+	mockFileInfo
+	count int
+}
+
+func (s *mockStatFileInfo) Size() int64 {
+	s.count++
+	return s.mockFileInfo.Size()
+}
+
+func (s *mockStatFileInfo) Mode() fs.FileMode {
+	s.count++
+	return s.mockFileInfo.Mode()
+}
+
+func (s *mockStatFileInfo) ModTime() time.Time {
+	s.count++
+	return s.mockFileInfo.ModTime()
+}
+
+func (s *mockStatFileInfo) Sys() interface{} {
+	s.count++
+	return s.mockFileInfo.Sys()
+}
+
+func TestSpillNoStat(t *testing.T) {
+	for _, tc := range []*struct {
+		title string
+		query string
+		want  []string
+	}{
+		{
+			title: "order by",
+			query: "select name order by name desc;",
+			want:  []string{"c", "b", "a"},
+		},
+		{
+			title: "group by",
+			query: "select is_dir, count(name) group by is_dir;",
+			want:  []string{"false 3"},
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			infos := []*mockStatFileInfo{
+				{mockFileInfo: mockFileInfo{name: "b", size: 1}},
+				{mockFileInfo: mockFileInfo{name: "a", size: 2}},
+				{mockFileInfo: mockFileInfo{name: "c", size: 3}},
+			}
+			digger := &mockDigger{}
+			for _, x := range infos {
+				digger.infos = append(digger.infos, x)
+			}
+			dir := t.TempDir()
+			runner := eval.NewRunner(parseStatement(t, tc.query), digger,
+				eval.WithMemoryBudget(1), eval.WithTempDir(dir))
+			got := []string{}
+			for r := range runner.Run(context.TODO(), ".") {
+				if !assert.Nil(t, r.Err()) {
+					return
+				}
+				xs := make([]string, r.Len())
+				for i := range xs {
+					xs[i] = fmt.Sprint(r.Get(i).Value())
+				}
+				got = append(got, strings.Join(xs, " "))
+			}
+			assert.Equal(t, tc.want, got)
+			for _, x := range infos {
+				assert.Equal(t, 0, x.count, "%s is stat'ed", x.name)
+			}
+			assertEmptyDir(t, dir)
+		})
+	}
+}
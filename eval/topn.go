@@ -0,0 +1,122 @@
+package eval
+
+import (
+	"container/heap"
+	"context"
+	"sort"
+
+	"github.com/berquerant/dql/ast"
+	"github.com/berquerant/dql/async"
+	"github.com/berquerant/dql/data"
+	"github.com/berquerant/dql/env"
+	"github.com/berquerant/dql/errors"
+)
+
+type (
+	topN struct {
+		*orderBy
+		n int
+	}
+
+	// topNHeap is a heap whose top is the last row of the rows kept.
+	topNHeap struct {
+		rows   []*topNRow
+		before func(a, b *topNRow) bool
+	}
+
+	topNRow struct {
+		*orderByRow
+		seq int // arrival order to keep the sort stable
+	}
+)
+
+// NewTopN returns a new OrderBy that yields only the first n rows of the sorted rows,
+// the same as the first n rows from NewOrderBy.
+// The memory is bounded by n.
+func NewTopN(evaluator Evaluator, n int) OrderBy {
+	return &topN{
+		orderBy: &orderBy{
+			evaluator: evaluator,
+		},
+		n: n,
+	}
+}
+
+func (s *topN) Sort(
+	ctx context.Context, table env.Map, expr ast.Expr, isDesc bool, sourceC <-chan GRow,
+) <-chan GRow {
+	resultC := make(chan GRow, resultCBufferSize)
+	e := s.evaluator.Prepare(table, expr)
+	go func() {
+		defer close(resultC)
+		defer drainGRows(sourceC)
+		var (
+			h   = &topNHeap{}
+			seq int
+		)
+		for r := range sourceC {
+			if async.IsDone(ctx) {
+				resultC <- NewErrGRow(errors.Wrap(ctx.Err(), "order by"))
+				return
+			}
+			v, err := s.evalRow(e, r)
+			if err != nil {
+				resultC <- NewErrGRow(errors.Wrap(err, "order by"))
+				return
+			}
+			x := &topNRow{
+				orderByRow: v,
+				seq:        seq,
+			}
+			seq++
+			if h.before == nil {
+				// the type of the first row decides the order, as orderBy
+				if h.before, err = topNBeforeFunc(v.value.Type(), isDesc); err != nil {
+					resultC <- NewErrGRow(err)
+					return
+				}
+			}
+			switch {
+			case h.Len() < s.n:
+				heap.Push(h, x)
+			case s.n > 0 && h.before(x, h.rows[0]):
+				h.rows[0] = x
+				heap.Fix(h, 0)
+			}
+		}
+		sort.Slice(h.rows, func(i, j int) bool { return h.before(h.rows[i], h.rows[j]) })
+		for _, r := range h.rows {
+			resultC <- r.row
+		}
+	}()
+	return resultC
+}
+
+// topNBeforeFunc returns the function that returns true if a should be yielded before b.
+func topNBeforeFunc(typ data.Type, isDesc bool) (func(a, b *topNRow) bool, error) {
+	less, err := lessFunc(typ, isDesc)
+	if err != nil {
+		return nil, err
+	}
+	return func(a, b *topNRow) bool {
+		if less(a.value, b.value) {
+			return true
+		}
+		if less(b.value, a.value) {
+			return false
+		}
+		return a.seq < b.seq
+	}, nil
+}
+
+func (s *topNHeap) Len() int           { return len(s.rows) }
+func (s *topNHeap) Less(i, j int) bool { return s.before(s.rows[j], s.rows[i]) }
+func (s *topNHeap) Swap(i, j int)      { s.rows[i], s.rows[j] = s.rows[j], s.rows[i] }
+func (s *topNHeap) Push(x interface{}) { s.rows = append(s.rows, x.(*topNRow)) }
+func (s *topNHeap) Pop() interface{} {
+	n := len(s.rows)
+	x := s.rows[n-1]
+	s.rows[n-1] = nil
+	s.rows = s.rows[:n-1]
+	return x
+}
@@ -0,0 +1,68 @@
+package eval_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/berquerant/dql/calc"
+	"github.com/berquerant/dql/data"
+	"github.com/berquerant/dql/env"
+	"github.com/berquerant/dql/eval"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTopN(t *testing.T) {
+	var (
+		values = []data.Data{
+			data.FromInt(3),
+			data.FromInt(1),
+			data.FromInt(3),
+			data.FromInt(5),
+			data.FromInt(1),
+			data.FromInt(4),
+			data.FromInt(3),
+		}
+		factory = func() eval.Evaluator {
+			c := &mockMultipleCalculator{
+				values: values,
+			}
+			return eval.NewInterpreter(func(_ env.Map) calc.Calculator {
+				return c
+			})
+		}
+		yield = func() <-chan eval.GRow {
+			c := make(chan eval.GRow, len(values))
+			for i := range values {
+				c <- eval.NewRawGRow(eval.NewRow(&mockInfo{
+					name: fmt.Sprint(i),
+				}))
+			}
+			close(c)
+			return c
+		}
+		names = func(rows []eval.GRow) []string {
+			r := make([]string, len(rows))
+			for i, x := range rows {
+				r[i] = x.Raw().Info().Name()
+			}
+			return r
+		}
+	)
+
+	for _, isDesc := range []bool{false, true} {
+		want := names(resultToGRows(eval.NewOrderBy(factory()).Sort(context.TODO(), env.New(), nil, isDesc, yield())))
+		for _, n := range []int{0, 1, 2, 3, 4, len(values), len(values) + 1} {
+			isDesc := isDesc
+			n := n
+			t.Run(fmt.Sprintf("desc %v n %d", isDesc, n), func(t *testing.T) {
+				got := names(resultToGRows(eval.NewTopN(factory(), n).Sort(context.TODO(), env.New(), nil, isDesc, yield())))
+				w := want
+				if n < len(w) {
+					w = w[:n]
+				}
+				assert.Equal(t, w, got)
+			})
+		}
+	}
+}
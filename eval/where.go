@@ -5,7 +5,6 @@ import (
 
 	"github.com/berquerant/dql/ast"
 	"github.com/berquerant/dql/async"
-	"github.com/berquerant/dql/calc"
 	"github.com/berquerant/dql/data"
 	"github.com/berquerant/dql/env"
 	"github.com/berquerant/dql/errors"
@@ -17,40 +16,40 @@ type (
 	}
 
 	where struct {
-		calcFactory func(env.Map) calc.Calculator
+		evaluator Evaluator
+		workers   int
 	}
 )
 
-func NewWhere(calcFactory func(env.Map) calc.Calculator) Where {
+func NewWhere(evaluator Evaluator) Where {
+	return NewParallelWhere(evaluator, 1)
+}
+
+// NewParallelWhere returns a new Where that evaluates the rows with the workers concurrently.
+// The order of the rows is preserved.
+func NewParallelWhere(evaluator Evaluator, workers int) Where {
+	if workers < 1 {
+		workers = 1
+	}
 	return &where{
-		calcFactory: calcFactory,
+		evaluator: evaluator,
+		workers:   workers,
 	}
 }
 
 func (s *where) Filter(ctx context.Context, table env.Map, expr ast.Expr, sourceC <-chan Row) <-chan Row {
 	resultC := make(chan Row, resultCBufferSize)
+	if s.workers > 1 {
+		s.filterParallel(ctx, table, expr, sourceC, resultC)
+		return resultC
+	}
+	e := s.evaluator.Prepare(table, expr)
 	go func() {
 		defer close(resultC)
+		defer drainRows(sourceC)
 		for row := range sourceC {
-			if async.IsDone(ctx) {
-				resultC <- NewErrRow(errors.Wrap(ctx.Err(), "where"))
-				return
-			}
-			if err := row.Err(); err != nil {
-				resultC <- NewErrRow(errors.Wrap(err, "where"))
-				return
-			}
-			t := AppendRowToEnv(table, row)
-			err := s.filter(t, expr)
-			switch {
-			case err == nil:
-				// accepted
-				resultC <- row
-			case errors.Is(err, errFiltered):
-				// denied
-				continue
-			default:
-				resultC <- NewErrRow(errors.Wrap(err, "where"))
+			row := row
+			if !s.emit(ctx, resultC, row, func() error { return s.filter(e, row) }) {
 				return
 			}
 		}
@@ -58,8 +57,70 @@ func (s *where) Filter(ctx context.Context, table env.Map, expr ast.Expr, source
 	return resultC
 }
 
-func (s *where) filter(table env.Map, expr ast.Expr) error {
-	r, err := s.calcFactory(table).Data(expr)
+func (s *where) filterParallel(ctx context.Context, table env.Map, expr ast.Expr, sourceC <-chan Row, resultC chan<- Row) {
+	es := make([]Evaluation, s.workers)
+	for i := range es {
+		es[i] = s.evaluator.Prepare(table, expr)
+	}
+	type result struct {
+		row Row
+		err error
+	}
+	go func() {
+		defer close(resultC)
+		defer drainRows(sourceC)
+		runOrdered(
+			s.workers,
+			func() (interface{}, bool) {
+				row, ok := <-sourceC
+				return row, ok
+			},
+			func(worker int, item interface{}) interface{} {
+				row := item.(Row)
+				if row.Err() != nil {
+					return &result{row: row}
+				}
+				return &result{
+					row: row,
+					err: s.filter(es[worker], row),
+				}
+			},
+			func(x interface{}) bool {
+				r := x.(*result)
+				return s.emit(ctx, resultC, r.row, func() error { return r.err })
+			},
+		)
+	}()
+}
+
+// emit sends the row to resultC if filter accepts it.
+// Returns false if the rows should not be read anymore.
+func (s *where) emit(ctx context.Context, resultC chan<- Row, row Row, filter func() error) bool {
+	if async.IsDone(ctx) {
+		resultC <- NewErrRow(errors.Wrap(ctx.Err(), "where"))
+		return false
+	}
+	if err := row.Err(); err != nil {
+		resultC <- NewErrRow(errors.Wrap(err, "where"))
+		return false
+	}
+	err := filter()
+	switch {
+	case err == nil:
+		// accepted
+		resultC <- row
+		return true
+	case errors.Is(err, errFiltered):
+		// denied
+		return true
+	default:
+		resultC <- NewErrRow(errors.Wrap(err, "where"))
+		return false
+	}
+}
+
+func (s *where) filter(e Evaluation, row Row) error {
+	r, err := e.Row(row)
 	if err != nil {
 		return err
 	}
@@ -39,29 +39,57 @@ type mockInfo struct {
 	mode    string
 	modTime int
 	isDir   bool
+	root    string
+	relName string
+	depth   int
 }
 
-func (s *mockInfo) Name() string { return s.name }
-func (s *mockInfo) Size() int    { return s.size }
-func (s *mockInfo) Mode() string { return s.mode }
-func (s *mockInfo) ModTime() int { return s.modTime }
-func (s *mockInfo) IsDir() bool  { return s.isDir }
+func (s *mockInfo) Name() string       { return s.name }
+func (s *mockInfo) Size() int          { return s.size }
+func (s *mockInfo) Mode() string       { return s.mode }
+func (s *mockInfo) ModTime() int       { return s.modTime }
+func (s *mockInfo) IsDir() bool        { return s.isDir }
+func (s *mockInfo) Root() string       { return s.root }
+func (s *mockInfo) RelName() string    { return s.relName }
+func (s *mockInfo) DepthFromRoot() int { return s.depth }
+func (*mockInfo) Inode() int           { return 0 }
+func (*mockInfo) Dev() int             { return 0 }
+func (*mockInfo) Nlink() int           { return 0 }
+func (*mockInfo) UID() int             { return 0 }
+func (*mockInfo) GID() int             { return 0 }
+func (*mockInfo) Owner() string        { return "" }
+func (*mockInfo) Group() string        { return "" }
+func (*mockInfo) Atime() int           { return 0 }
+func (*mockInfo) Ctime() int           { return 0 }
+func (*mockInfo) Blocks() int          { return 0 }
+func (*mockInfo) DiskUsage() int       { return 0 }
+func (*mockInfo) IsSymlink() bool      { return false }
+func (*mockInfo) LinkTarget() string   { return "" }
+func (*mockInfo) IsBrokenLink() bool   { return false }
+func (*mockInfo) Type() string         { return "" }
+func (*mockInfo) Perm() string         { return "" }
+func (*mockInfo) ModeBits() int        { return 0 }
+func (*mockInfo) IsIgnored() bool      { return false }
+func (*mockInfo) IsHidden() bool       { return false }
 func (s *mockInfo) ToMap() map[string]data.Data {
 	return map[string]data.Data{
-		"name":     data.FromString(s.name),
-		"size":     data.FromInt(s.size),
-		"mode":     data.FromString(s.mode),
-		"mod_time": data.FromInt(s.modTime),
-		"is_dir":   data.FromBool(s.isDir),
+		"name":            data.FromString(s.name),
+		"size":            data.FromInt(s.size),
+		"mode":            data.FromString(s.mode),
+		"mod_time":        data.FromInt(s.modTime),
+		"is_dir":          data.FromBool(s.isDir),
+		"root":            data.FromString(s.root),
+		"rel_name":        data.FromString(s.relName),
+		"depth_from_root": data.FromInt(s.depth),
 	}
 }
 
 func TestWhere(t *testing.T) {
 	var (
-		factory = func(c calc.Calculator) func(env.Map) calc.Calculator {
-			return func(_ env.Map) calc.Calculator {
+		factory = func(c calc.Calculator) eval.Evaluator {
+			return eval.NewInterpreter(func(_ env.Map) calc.Calculator {
 				return c
-			}
+			})
 		}
 		infoRow = &mockRow{
 			info: &mockInfo{
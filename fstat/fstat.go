@@ -0,0 +1,72 @@
+package fstat
+
+import (
+	"io/fs"
+	"os/user"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// BlockSize is the size of a block in bytes, the unit of Stat.Blocks.
+const BlockSize = 512
+
+// Stat is the platform dependent status of a file.
+type Stat struct {
+	Dev    uint64
+	Inode  uint64
+	Nlink  uint64
+	UID    uint32
+	GID    uint32
+	Atime  time.Time
+	Ctime  time.Time
+	Blocks int64
+}
+
+// FromFileInfo extracts Stat from the underlying data source of v.
+// Returns false if it is not available.
+func FromFileInfo(v fs.FileInfo) (*Stat, bool) {
+	if v == nil {
+		return nil, false
+	}
+	return FromSys(v.Sys())
+}
+
+// FromSys extracts Stat from the underlying data source of fs.FileInfo.
+// Returns false if it is not available.
+func FromSys(sys interface{}) (*Stat, bool) { return fromSys(sys) }
+
+var (
+	userNames  sync.Map
+	groupNames sync.Map
+)
+
+// UserName returns the name of the user.
+// Returns uid as a string if the user is not found.
+func UserName(uid uint32) string {
+	if v, ok := userNames.Load(uid); ok {
+		return v.(string)
+	}
+	id := strconv.FormatUint(uint64(uid), 10)
+	name := id
+	if u, err := user.LookupId(id); err == nil {
+		name = u.Username
+	}
+	userNames.Store(uid, name)
+	return name
+}
+
+// GroupName returns the name of the group.
+// Returns gid as a string if the group is not found.
+func GroupName(gid uint32) string {
+	if v, ok := groupNames.Load(gid); ok {
+		return v.(string)
+	}
+	id := strconv.FormatUint(uint64(gid), 10)
+	name := id
+	if g, err := user.LookupGroupId(id); err == nil {
+		name = g.Name
+	}
+	groupNames.Store(gid, name)
+	return name
+}
@@ -0,0 +1,26 @@
+//go:build linux
+// +build linux
+
+package fstat
+
+import (
+	"syscall"
+	"time"
+)
+
+func fromSys(sys interface{}) (*Stat, bool) {
+	st, ok := sys.(*syscall.Stat_t)
+	if !ok {
+		return nil, false
+	}
+	return &Stat{
+		Dev:    uint64(st.Dev),
+		Inode:  uint64(st.Ino),
+		Nlink:  uint64(st.Nlink),
+		UID:    st.Uid,
+		GID:    st.Gid,
+		Atime:  time.Unix(int64(st.Atim.Sec), int64(st.Atim.Nsec)),
+		Ctime:  time.Unix(int64(st.Ctim.Sec), int64(st.Ctim.Nsec)),
+		Blocks: int64(st.Blocks),
+	}, true
+}
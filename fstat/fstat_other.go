@@ -0,0 +1,6 @@
+//go:build !linux && !darwin
+// +build !linux,!darwin
+
+package fstat
+
+func fromSys(_ interface{}) (*Stat, bool) { return nil, false }
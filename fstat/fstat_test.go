@@ -0,0 +1,53 @@
+package fstat_test
+
+import (
+	"os"
+	"os/user"
+	"runtime"
+	"strconv"
+	"testing"
+
+	"github.com/berquerant/dql/fstat"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromFileInfo(t *testing.T) {
+	if !(runtime.GOOS == "linux" || runtime.GOOS == "darwin") {
+		t.Skipf("not available on %s", runtime.GOOS)
+	}
+	f, err := os.CreateTemp("", "fstattest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.WriteString("fstat"); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, ok := fstat.FromFileInfo(info)
+	assert.True(t, ok)
+	assert.NotEqual(t, uint64(0), got.Inode)
+	assert.Equal(t, uint64(1), got.Nlink)
+	assert.Equal(t, uint32(os.Getuid()), got.UID)
+	assert.False(t, got.Ctime.IsZero())
+}
+
+func TestUserName(t *testing.T) {
+	u, err := user.Current()
+	if err != nil {
+		t.Skip(err)
+	}
+	uid, err := strconv.ParseUint(u.Uid, 10, 32)
+	if err != nil {
+		t.Skip(err)
+	}
+	assert.Equal(t, u.Username, fstat.UserName(uint32(uid)))
+	assert.Equal(t, "4000000000", fstat.UserName(4000000000))
+}
@@ -0,0 +1,41 @@
+package fstat
+
+import "io/fs"
+
+// FileType returns the name of the entry type of the mode.
+func FileType(mode fs.FileMode) string {
+	switch {
+	case mode.IsRegular():
+		return "file"
+	case mode&fs.ModeDir != 0:
+		return "dir"
+	case mode&fs.ModeSymlink != 0:
+		return "symlink"
+	case mode&fs.ModeNamedPipe != 0:
+		return "fifo"
+	case mode&fs.ModeSocket != 0:
+		return "socket"
+	case mode&fs.ModeCharDevice != 0:
+		return "char_device"
+	case mode&fs.ModeDevice != 0:
+		return "block_device"
+	default:
+		return "unknown"
+	}
+}
+
+// PermBits returns the permission bits of the mode as unix mode bits,
+// including setuid, setgid and sticky bits.
+func PermBits(mode fs.FileMode) int {
+	bits := int(mode.Perm())
+	if mode&fs.ModeSetuid != 0 {
+		bits |= 04000
+	}
+	if mode&fs.ModeSetgid != 0 {
+		bits |= 02000
+	}
+	if mode&fs.ModeSticky != 0 {
+		bits |= 01000
+	}
+	return bits
+}
@@ -0,0 +1,49 @@
+package fstat_test
+
+import (
+	"io/fs"
+	"testing"
+
+	"github.com/berquerant/dql/fstat"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileType(t *testing.T) {
+	for _, tc := range []*struct {
+		mode fs.FileMode
+		want string
+	}{
+		{mode: 0644, want: "file"},
+		{mode: fs.ModeDir | 0755, want: "dir"},
+		{mode: fs.ModeSymlink | 0777, want: "symlink"},
+		{mode: fs.ModeNamedPipe, want: "fifo"},
+		{mode: fs.ModeSocket, want: "socket"},
+		{mode: fs.ModeDevice | fs.ModeCharDevice, want: "char_device"},
+		{mode: fs.ModeDevice, want: "block_device"},
+		{mode: fs.ModeIrregular, want: "unknown"},
+	} {
+		tc := tc
+		t.Run(tc.want, func(t *testing.T) {
+			assert.Equal(t, tc.want, fstat.FileType(tc.mode))
+		})
+	}
+}
+
+func TestPermBits(t *testing.T) {
+	for _, tc := range []*struct {
+		title string
+		mode  fs.FileMode
+		want  int
+	}{
+		{title: "file", mode: 0644, want: 0644},
+		{title: "dir", mode: fs.ModeDir | 0755, want: 0755},
+		{title: "setuid", mode: fs.ModeSetuid | 0755, want: 04755},
+		{title: "setgid", mode: fs.ModeSetgid | 0755, want: 02755},
+		{title: "sticky", mode: fs.ModeDir | fs.ModeSticky | 0777, want: 01777},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			assert.Equal(t, tc.want, fstat.PermBits(tc.mode))
+		})
+	}
+}
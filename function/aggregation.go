@@ -14,22 +14,21 @@ type Aggregation interface {
 	IsAggregation()
 }
 
-func AggregationFunctionNames() []string {
-	return []string{
-		"count",
-		"min",
-		"max",
-		"product",
-		"sum",
-		"avg",
-	}
-}
-
 //go:generate marker -method IsAggregation -output aggregation_marker_generated.go -type count,min,max,sum,product,avg
 
+var countSignature = &Signature{
+	Name:        "count",
+	Params:      []Param{{Name: "x", Type: TypeAny}},
+	Returns:     TypeInt,
+	NonNullable: true,
+	Aggregation: true,
+	Doc:         "number of the rows",
+	Example:     "count(name)",
+}
+
 // NewCount returns a new count function.
 // It counts up the length of the arguments.
-func NewCount() Aggregation { return &count{} }
+func NewCount() Aggregation { return newValidatedAggregation(countSignature, &count{}) }
 
 type count struct{}
 
@@ -38,12 +37,23 @@ func (*count) Call(args ...data.Data) (data.Data, error) {
 	return data.FromInt(len(args)), nil
 }
 
+var minSignature = &Signature{
+	Name:        "min",
+	Params:      []Param{{Name: "x", Type: TypeAny}},
+	Returns:     TypeAny,
+	ReturnKind:  "any (same type)",
+	ResultOf:    SameAsArg,
+	Aggregation: true,
+	Doc:         "minimum of the rows",
+	Example:     "min(size)",
+}
+
 // NewMin returns a new min function.
 // It returns the minimum value of the arguments.
 func NewMin(comparer compare.Comparer) Aggregation {
-	return &min{
+	return newValidatedAggregation(minSignature, &min{
 		comparer: comparer,
-	}
+	})
 }
 
 type min struct {
@@ -73,12 +83,23 @@ func (s *min) Call(args ...data.Data) (data.Data, error) {
 	}
 }
 
+var maxSignature = &Signature{
+	Name:        "max",
+	Params:      []Param{{Name: "x", Type: TypeAny}},
+	Returns:     TypeAny,
+	ReturnKind:  "any (same type)",
+	ResultOf:    SameAsArg,
+	Aggregation: true,
+	Doc:         "maximum of the rows",
+	Example:     "max(name)",
+}
+
 // NewMax returns a new max function.
 // It returns the maximum value of the arguments.
 func NewMax(comparer compare.Comparer) Aggregation {
-	return &max{
+	return newValidatedAggregation(maxSignature, &max{
 		comparer: comparer,
-	}
+	})
 }
 
 type max struct {
@@ -108,12 +129,22 @@ func (s *max) Call(args ...data.Data) (data.Data, error) {
 	}
 }
 
+var productSignature = &Signature{
+	Name:        "product",
+	Params:      []Param{{Name: "x", Type: TypeNumber}},
+	Returns:     TypeNumber,
+	ResultOf:    IntIfInt,
+	Aggregation: true,
+	Doc:         "product of the rows",
+	Example:     "product(size)",
+}
+
 // NewProduct returns a new product function.
 // It returns the product of the arguments.
 func NewProduct(calculator arithmetic.Calculator) Aggregation {
-	return &product{
+	return newValidatedAggregation(productSignature, &product{
 		calculator: calculator,
-	}
+	})
 }
 
 type product struct {
@@ -140,12 +171,22 @@ func (s *product) Call(args ...data.Data) (data.Data, error) {
 	return data.FromFloat(acc), nil
 }
 
+var sumSignature = &Signature{
+	Name:        "sum",
+	Params:      []Param{{Name: "x", Type: TypeNumber}},
+	Returns:     TypeNumber,
+	ResultOf:    IntIfInt,
+	Aggregation: true,
+	Doc:         "summation of the rows",
+	Example:     "sum(size)",
+}
+
 // NewSum returns a new sum function.
 // It returns the sum of the arguments.
 func NewSum(calculator arithmetic.Calculator) Aggregation {
-	return &sum{
+	return newValidatedAggregation(sumSignature, &sum{
 		calculator: calculator,
-	}
+	})
 }
 
 type sum struct {
@@ -172,13 +213,22 @@ func (s *sum) Call(args ...data.Data) (data.Data, error) {
 	return data.FromFloat(acc), nil
 }
 
+var avgSignature = &Signature{
+	Name:        "avg",
+	Params:      []Param{{Name: "x", Type: TypeNumber}},
+	Returns:     TypeNumber,
+	Aggregation: true,
+	Doc:         "average of the rows",
+	Example:     "avg(size)",
+}
+
 // NewAvg returns a new avg function.
 // It returns the average of the arguments.
 func NewAvg(calculator arithmetic.Calculator, sum Aggregation) Aggregation {
-	return &avg{
+	return newValidatedAggregation(avgSignature, &avg{
 		sum:        sum,
 		calculator: calculator,
-	}
+	})
 }
 
 type avg struct {
@@ -39,46 +39,9 @@ func NewFactoryBuilder(
 }
 
 func (s *factoryBuidler) Factory(name string) (Factory, bool) {
-	switch strings.ToLower(name) {
-	case "now":
-		return NewNow, true
-	case "cast":
-		return func() Function { return NewCast(s.caster) }, true
-	case "int2bin":
-		return NewInt2Bin, true
-	case "bin2int":
-		return NewBin2Int, true
-	case "ext":
-		return NewExt, true
-	case "dir":
-		return NewDir, true
-	case "base":
-		return NewBase, true
-	case "len":
-		return NewLen, true
-	case "floor":
-		return NewFloor, true
-	case "ceil":
-		return NewCeil, true
-	case "depth":
-		return NewDepth, true
-	case "grep":
-		return func() Function { return NewGrep(s.grepper) }, true
-	case "pow":
-		return func() Function { return NewPow(s.artCalculator) }, true
-	case "count":
-		return func() Function { return NewCount() }, true
-	case "min":
-		return func() Function { return NewMin(s.comparer) }, true
-	case "max":
-		return func() Function { return NewMax(s.comparer) }, true
-	case "product":
-		return func() Function { return NewProduct(s.artCalculator) }, true
-	case "sum":
-		return func() Function { return NewSum(s.artCalculator) }, true
-	case "avg":
-		return func() Function { return NewAvg(s.artCalculator, NewSum(s.artCalculator)) }, true
-	default:
+	b, ok := builtinIndex[strings.ToLower(name)]
+	if !ok {
 		return nil, false
 	}
+	return func() Function { return b.new(s) }, true
 }
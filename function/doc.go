@@ -0,0 +1,111 @@
+package function
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/berquerant/dql/errors"
+)
+
+var (
+	ErrNoDocMarker = errors.New("no doc marker")
+)
+
+// WriteTable writes the markdown table of the signatures.
+func WriteTable(w io.Writer, signatures []*Signature) error {
+	rows := [][]string{{"Format", "Description", "Argument Types", "Result Type", "Example"}}
+	for _, s := range signatures {
+		rows = append(rows, []string{
+			s.Format(),
+			s.Doc,
+			s.ParamKinds(),
+			s.ResultKind(),
+			s.Example,
+		})
+	}
+	widths := make([]int, len(rows[0]))
+	for _, r := range rows {
+		for i, x := range r {
+			if len(x) > widths[i] {
+				widths[i] = len(x)
+			}
+		}
+	}
+	line := func(r []string) string {
+		xs := make([]string, len(r))
+		for i, x := range r {
+			xs[i] = x + strings.Repeat(" ", widths[i]-len(x))
+		}
+		return fmt.Sprintf("| %s |\n", strings.Join(xs, " | "))
+	}
+	rules := make([]string, len(widths))
+	for i, n := range widths {
+		rules[i] = strings.Repeat("-", n+2)
+	}
+
+	var b strings.Builder
+	b.WriteString(line(rows[0]))
+	b.WriteString(fmt.Sprintf("|%s|\n", strings.Join(rules, "|")))
+	for _, r := range rows[1:] {
+		b.WriteString(line(r))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteTables writes the tables of the normal functions and the aggregations.
+func WriteTables(w io.Writer) error {
+	if err := WriteTable(w, signatures(false)); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, "\n"); err != nil {
+		return err
+	}
+	return WriteTable(w, signatures(true))
+}
+
+const (
+	functionsDocMarker    = "functions"
+	aggregationsDocMarker = "aggregations"
+)
+
+// UpdateDoc replaces the tables in the document with the tables of the signatures.
+// The tables are between the markers:
+//
+//	<!-- functions begin -->
+//	<!-- functions end -->
+//	<!-- aggregations begin -->
+//	<!-- aggregations end -->
+func UpdateDoc(doc string) (string, error) {
+	var err error
+	for _, x := range []struct {
+		marker      string
+		aggregation bool
+	}{
+		{marker: functionsDocMarker},
+		{marker: aggregationsDocMarker, aggregation: true},
+	} {
+		var b strings.Builder
+		if err := WriteTable(&b, signatures(x.aggregation)); err != nil {
+			return "", err
+		}
+		if doc, err = replaceDocSection(doc, x.marker, b.String()); err != nil {
+			return "", err
+		}
+	}
+	return doc, nil
+}
+
+func replaceDocSection(doc, marker, content string) (string, error) {
+	var (
+		begin = fmt.Sprintf("<!-- %s begin -->\n", marker)
+		end   = fmt.Sprintf("<!-- %s end -->", marker)
+		i     = strings.Index(doc, begin)
+		j     = strings.Index(doc, end)
+	)
+	if i < 0 || j < i {
+		return "", errors.Wrap(ErrNoDocMarker, marker)
+	}
+	return doc[:i+len(begin)] + content + doc[j:], nil
+}
@@ -17,6 +17,22 @@ type (
 	}
 )
 
+// NewFunction returns a new Function named name that calls call.
+func NewFunction(name string, call func(args ...data.Data) (data.Data, error)) Function {
+	return &funcFunction{
+		name: name,
+		call: call,
+	}
+}
+
+type funcFunction struct {
+	name string
+	call func(args ...data.Data) (data.Data, error)
+}
+
+func (s *funcFunction) Name() string                              { return s.name }
+func (s *funcFunction) Call(args ...data.Data) (data.Data, error) { return s.call(args...) }
+
 func NewCaller(functions ...Function) Caller {
 	set := map[string]Function{}
 	for _, f := range functions {
@@ -53,6 +69,33 @@ func (s *caller) Func(name string) (Function, bool) {
 	return f, exist
 }
 
+// NewOverlayCaller returns a new Caller that finds the functions in functions first and then in base.
+func NewOverlayCaller(base Caller, functions ...Function) Caller {
+	return &overlayCaller{
+		base:  base,
+		local: NewCaller(functions...),
+	}
+}
+
+type overlayCaller struct {
+	base  Caller
+	local Caller
+}
+
+func (s *overlayCaller) Call(name string, args ...data.Data) (data.Data, error) {
+	if _, ok := s.local.Func(name); ok {
+		return s.local.Call(name, args...)
+	}
+	return s.base.Call(name, args...)
+}
+
+func (s *overlayCaller) Func(name string) (Function, bool) {
+	if f, ok := s.local.Func(name); ok {
+		return f, true
+	}
+	return s.base.Func(name)
+}
+
 func NewCallerWithNames(builder FactoryBuilder, functionNames ...string) Caller {
 	functions := []Function{}
 	for _, name := range functionNames {
@@ -13,34 +13,24 @@ import (
 	"github.com/berquerant/dql/chrono"
 	"github.com/berquerant/dql/data"
 	"github.com/berquerant/dql/errors"
-	"github.com/berquerant/dql/logger"
+	"github.com/berquerant/dql/glob"
 	"github.com/berquerant/gogrep"
 )
 
-func NormalFunctionNames() []string {
-	return []string{
-		"now",
-		"cast",
-		"int2bin",
-		"bin2int",
-		"ext",
-		"dir",
-		"base",
-		"floor",
-		"ceil",
-		"pow",
-		"grep",
-		"len",
-		"depth",
-	}
+var grepSignature = &Signature{
+	Name:    "grep",
+	Params:  []Param{{Name: "x", Type: TypeString}, {Name: "y", Type: TypeString}},
+	Returns: TypeInt,
+	Doc:     "the number of the lines of file y matching regex x",
+	Example: `grep("lambda", "map.py")`,
 }
 
 // NewGrep returns a new grep function.
 // It greps args[1] by args[0] and returns a count of selected lines.
 func NewGrep(grepper gogrep.Grepper) Function {
-	return &grep{
+	return newValidated(grepSignature, &grep{
 		grepper: grepper,
-	}
+	})
 }
 
 type grep struct {
@@ -49,17 +39,10 @@ type grep struct {
 
 func (*grep) Name() string { return "grep" }
 func (s *grep) Call(args ...data.Data) (data.Data, error) {
-	if len(args) != 2 {
-		return nil, errors.Wrap(ErrInvalidArgument, "arg len want 2 but got %d", len(args))
-	}
 	var (
 		pattern  = args[0]
 		filename = args[1]
 	)
-	if !(pattern.Type() == data.TypeString && filename.Type() == data.TypeString) {
-		return nil, errors.Wrap(ErrInvalidArgument,
-			"arg[0] want string and arg[1] want string but got %s %s", pattern.Type(), filename.Type())
-	}
 	f, err := os.Open(filename.String())
 	if err != nil {
 		return nil, errors.Wrap(err, "cannot grep")
@@ -79,44 +62,78 @@ func (s *grep) Call(args ...data.Data) (data.Data, error) {
 	return data.FromInt(c), nil
 }
 
+var depthSignature = &Signature{
+	Name:    "depth",
+	Params:  []Param{{Name: "x", Type: TypeString}},
+	Returns: TypeInt,
+	Doc:     "the depth of the path",
+	Example: `depth("/home/user")`,
+}
+
 // NewDepth returns a new depth function.
 // It returns the depth of the path.
-func NewDepth() Function { return &depth{} }
+func NewDepth() Function { return newValidated(depthSignature, &depth{}) }
 
 type depth struct{}
 
 func (*depth) Name() string { return "depth" }
 func (*depth) Call(args ...data.Data) (data.Data, error) {
-	if len(args) != 1 {
-		return nil, errors.Wrap(ErrInvalidArgument, "arg len want 1 but got %d", len(args))
-	}
-	arg := args[0]
-	if arg.Type() != data.TypeString {
-		return nil, errors.Wrap(ErrInvalidArgument, "arg want string but got %s", arg.Type())
-	}
-	return data.FromInt(strings.Count(arg.String(), "/")), nil
+	return data.FromInt(strings.Count(args[0].String(), "/")), nil
+}
+
+var nowSignature = &Signature{
+	Name:    "now",
+	Params:  []Param{},
+	Returns: TypeInt,
+	Doc:     "the current local time",
+	Example: "now()",
 }
 
 // NewNow returns a new now function.
 // It returns the current time as a timestamp.
-func NewNow() Function { return &now{} }
+func NewNow() Function { return newValidated(nowSignature, &now{}) }
 
 type now struct{}
 
 func (*now) Name() string { return "now" }
-func (*now) Call(args ...data.Data) (data.Data, error) {
-	if len(args) > 0 {
-		return nil, errors.Wrap(ErrInvalidArgument, "arg len want 0 but got %d", len(args))
-	}
+func (*now) Call(_ ...data.Data) (data.Data, error) {
 	return data.FromInt(chrono.Now().Unix()), nil
 }
 
+var castSignature = &Signature{
+	Name:     "cast",
+	Params:   []Param{{Name: "x", Type: TypeAny}, {Name: "y", Type: TypeString}},
+	Returns:  TypeAny,
+	ResultOf: castResult,
+	Doc:      "cast x to y",
+	Example:  `cast(10, "string")`,
+}
+
+// castResult returns the destination type if it is a literal.
+func castResult(args []Arg) Type {
+	if len(args) != 2 || args[1].Lit == nil || args[1].Lit.Type() != data.TypeString {
+		return TypeUnknown
+	}
+	switch castType(args[1].Lit.String()) {
+	case cast.TypeInt, cast.TypeTimestamp:
+		return TypeInt
+	case cast.TypeFloat:
+		return TypeFloat
+	case cast.TypeString, cast.TypeTime, cast.TypeDuration:
+		return TypeString
+	case cast.TypeBool:
+		return TypeBool
+	default:
+		return TypeUnknown
+	}
+}
+
 // NewCast returns a new cast function.
 // Converts args[0] as args[1].
 func NewCast(caster cast.Caster) Function {
-	return &casting{
+	return newValidated(castSignature, &casting{
 		caster: caster,
-	}
+	})
 }
 
 type casting struct {
@@ -125,24 +142,18 @@ type casting struct {
 
 func (*casting) Name() string { return "cast" }
 func (s *casting) Call(args ...data.Data) (data.Data, error) {
-	if len(args) != 2 {
-		return nil, errors.Wrap(ErrInvalidArgument, "arg len want 2 but got %d", len(args))
-	}
 	var (
 		arg = args[0]
 		to  = args[1]
 	)
-	if to.Type() != data.TypeString {
-		return nil, errors.Wrap(ErrInvalidArgument, "cast type want string but got %s", logger.JSON(to))
-	}
-	r, err := s.caster.Cast(arg, s.toType(to.String()))
+	r, err := s.caster.Cast(arg, castType(to.String()))
 	if err != nil {
 		return nil, errors.Wrap(err, "cannot cast")
 	}
 	return r, nil
 }
 
-func (*casting) toType(t string) cast.Type {
+func castType(t string) cast.Type {
 	switch strings.ToLower(t) {
 	case "int":
 		return cast.TypeInt
@@ -163,39 +174,81 @@ func (*casting) toType(t string) cast.Type {
 	}
 }
 
+var int2binSignature = &Signature{
+	Name:       "int2bin",
+	Params:     []Param{{Name: "x", Type: TypeInt}},
+	Returns:    TypeString,
+	ReturnKind: "bits",
+	Doc:        "int to bits",
+	Example:    "int2bin(10)",
+}
+
 // NewInt2Bin returns a new int2bin function.
 // It parses the integer as binary digits.
-func NewInt2Bin() Function { return &int2bin{} }
+func NewInt2Bin() Function { return newValidated(int2binSignature, &int2bin{}) }
 
 type int2bin struct{}
 
 func (*int2bin) Name() string { return "int2bin" }
 func (s *int2bin) Call(args ...data.Data) (data.Data, error) {
-	if len(args) != 1 {
-		return nil, errors.Wrap(ErrInvalidArgument, "arg len want 1 but got %d", len(args))
-	}
-	arg := args[0]
-	if arg.Type() != data.TypeInt {
-		return nil, errors.Wrap(ErrInvalidArgument, "arg type want int but got %s", arg.Type())
-	}
-	return data.FromString(bit.ToBinaryString(arg.Int())), nil
+	return data.FromString(bit.ToBinaryString(args[0].Int())), nil
+}
+
+var int2octSignature = &Signature{
+	Name:    "int2oct",
+	Params:  []Param{{Name: "x", Type: TypeInt}},
+	Returns: TypeString,
+	Doc:     "int to octal digits",
+	Example: "int2oct(0o755)",
+}
+
+// NewInt2Oct returns a new int2oct function.
+// It formats the integer as octal digits.
+func NewInt2Oct() Function { return newValidated(int2octSignature, &int2oct{}) }
+
+type int2oct struct{}
+
+func (*int2oct) Name() string { return "int2oct" }
+func (*int2oct) Call(args ...data.Data) (data.Data, error) {
+	return data.FromString(bit.ToOctalString(args[0].Int())), nil
+}
+
+var int2hexSignature = &Signature{
+	Name:    "int2hex",
+	Params:  []Param{{Name: "x", Type: TypeInt}},
+	Returns: TypeString,
+	Doc:     "int to hex digits",
+	Example: "int2hex(0x1ff)",
+}
+
+// NewInt2Hex returns a new int2hex function.
+// It formats the integer as hex digits.
+func NewInt2Hex() Function { return newValidated(int2hexSignature, &int2hex{}) }
+
+type int2hex struct{}
+
+func (*int2hex) Name() string { return "int2hex" }
+func (*int2hex) Call(args ...data.Data) (data.Data, error) {
+	return data.FromString(bit.ToHexString(args[0].Int())), nil
+}
+
+var bin2intSignature = &Signature{
+	Name:    "bin2int",
+	Params:  []Param{{Name: "x", Type: TypeString, Kind: "bits"}},
+	Returns: TypeInt,
+	Doc:     "bits to int",
+	Example: `bin2int("1010")`,
 }
 
 // NewBin2Int returns a new bin2int function.
 // It parses the string as binary digits.
-func NewBin2Int() Function { return &bin2int{} }
+func NewBin2Int() Function { return newValidated(bin2intSignature, &bin2int{}) }
 
 type bin2int struct{}
 
 func (*bin2int) Name() string { return "bin2int" }
 func (*bin2int) Call(args ...data.Data) (data.Data, error) {
-	if len(args) != 1 {
-		return nil, errors.Wrap(ErrInvalidArgument, "arg len want 1 but got %d", len(args))
-	}
 	arg := args[0]
-	if arg.Type() != data.TypeString {
-		return nil, errors.Wrap(ErrInvalidArgument, "arg type want string but got %s", arg.Type())
-	}
 	r, err := bit.FromBinaryString(arg.String())
 	if err != nil {
 		return nil, errors.Wrap(ErrInvalidArgument, "arg %s", arg.String())
@@ -203,125 +256,303 @@ func (*bin2int) Call(args ...data.Data) (data.Data, error) {
 	return data.FromInt(r), nil
 }
 
+var extSignature = &Signature{
+	Name:    "ext",
+	Params:  []Param{{Name: "x", Type: TypeString}},
+	Returns: TypeString,
+	Doc:     "the file name extension",
+	Example: `ext("dired.elc")`,
+}
+
 // NewExt returns a new ext function.
 // It returns the extension of given file path.
-func NewExt() Function { return &ext{} }
+func NewExt() Function { return newValidated(extSignature, &ext{}) }
 
 type ext struct{}
 
 func (*ext) Name() string { return "ext" }
 func (*ext) Call(args ...data.Data) (data.Data, error) {
-	if len(args) != 1 {
-		return nil, errors.Wrap(ErrInvalidArgument, "arg len want 1 but got %d", len(args))
-	}
-	arg := args[0]
-	if arg.Type() != data.TypeString {
-		return nil, errors.Wrap(ErrInvalidArgument, "arg type want string but got %s", arg.Type())
-	}
-	return data.FromString(filepath.Base(arg.String())), nil
+	return data.FromString(filepath.Base(args[0].String())), nil
+}
+
+var dirSignature = &Signature{
+	Name:    "dir",
+	Params:  []Param{{Name: "x", Type: TypeString}},
+	Returns: TypeString,
+	Doc:     "all but the last element of path",
+	Example: `dir("dir/file")`,
 }
 
 // NewBase returns a new dir function.
 // It returns the directory of given file path.
-func NewDir() Function { return &dir{} }
+func NewDir() Function { return newValidated(dirSignature, &dir{}) }
 
 type dir struct{}
 
 func (*dir) Name() string { return "dir" }
 func (*dir) Call(args ...data.Data) (data.Data, error) {
-	if len(args) != 1 {
-		return nil, errors.Wrap(ErrInvalidArgument, "arg len want 1 but got %d", len(args))
-	}
-	arg := args[0]
-	if arg.Type() != data.TypeString {
-		return nil, errors.Wrap(ErrInvalidArgument, "arg type want string but got %s", arg.Type())
-	}
-	return data.FromString(filepath.Dir(arg.String())), nil
+	return data.FromString(filepath.Dir(args[0].String())), nil
+}
+
+var baseSignature = &Signature{
+	Name:    "base",
+	Params:  []Param{{Name: "x", Type: TypeString}},
+	Returns: TypeString,
+	Doc:     "the last element of path",
+	Example: `base("dir/file")`,
 }
 
 // NewBase returns a new base function.
 // It returns the last element of given file path.
-func NewBase() Function { return &base{} }
+func NewBase() Function { return newValidated(baseSignature, &base{}) }
 
 type base struct{}
 
 func (*base) Name() string { return "base" }
 func (*base) Call(args ...data.Data) (data.Data, error) {
-	if len(args) != 1 {
-		return nil, errors.Wrap(ErrInvalidArgument, "arg len want 1 but got %d", len(args))
+	return data.FromString(filepath.Base(args[0].String())), nil
+}
+
+var stemSignature = &Signature{
+	Name:    "stem",
+	Params:  []Param{{Name: "x", Type: TypeString}},
+	Returns: TypeString,
+	Doc:     "the last element of path without the extension",
+	Example: `stem("dir/a.tar.gz")`,
+}
+
+// NewStem returns a new stem function.
+// It returns the last element of given file path without the extension.
+func NewStem() Function { return newValidated(stemSignature, &stem{}) }
+
+type stem struct{}
+
+func (*stem) Name() string { return "stem" }
+func (*stem) Call(args ...data.Data) (data.Data, error) {
+	b := filepath.Base(args[0].String())
+	return data.FromString(strings.TrimSuffix(b, filepath.Ext(b))), nil
+}
+
+var relPathSignature = &Signature{
+	Name:    "relpath",
+	Params:  []Param{{Name: "x", Type: TypeString}, {Name: "y", Type: TypeString}},
+	Returns: TypeString,
+	Doc:     "x relative to y",
+	Example: "relpath(name, root)",
+}
+
+// NewRelPath returns a new relpath function.
+// It returns args[0] relative to args[1].
+// Relative paths are resolved from the current directory.
+func NewRelPath() Function { return newValidated(relPathSignature, &relPath{}) }
+
+type relPath struct{}
+
+func (*relPath) Name() string { return "relpath" }
+func (*relPath) Call(args ...data.Data) (data.Data, error) {
+	var (
+		target = args[0]
+		root   = args[1]
+	)
+	t, err := filepath.Abs(target.String())
+	if err != nil {
+		return nil, errors.Wrap(err, "relpath target %s", target.String())
 	}
-	arg := args[0]
-	if arg.Type() != data.TypeString {
-		return nil, errors.Wrap(ErrInvalidArgument, "arg type want string but got %s", arg.Type())
+	r, err := filepath.Abs(root.String())
+	if err != nil {
+		return nil, errors.Wrap(err, "relpath root %s", root.String())
+	}
+	p, err := filepath.Rel(r, t)
+	if err != nil {
+		return nil, errors.Wrap(err, "relpath %s from %s", t, r)
+	}
+	return data.FromString(p), nil
+}
+
+var pathJoinSignature = &Signature{
+	Name:     "path_join",
+	Params:   []Param{{Name: "x", Type: TypeString}},
+	Variadic: true,
+	Returns:  TypeString,
+	Doc:      "join elements into a path",
+	Example:  `path_join(dir(name), "go.mod")`,
+}
+
+// NewPathJoin returns a new path_join function.
+// It joins the arguments into a path.
+func NewPathJoin() Function { return newValidated(pathJoinSignature, &pathJoin{}) }
+
+type pathJoin struct{}
+
+func (*pathJoin) Name() string { return "path_join" }
+func (*pathJoin) Call(args ...data.Data) (data.Data, error) {
+	elems := make([]string, len(args))
+	for i, a := range args {
+		elems[i] = a.String()
+	}
+	return data.FromString(filepath.Join(elems...)), nil
+}
+
+var pathCleanSignature = &Signature{
+	Name:    "path_clean",
+	Params:  []Param{{Name: "x", Type: TypeString}},
+	Returns: TypeString,
+	Doc:     "the shortest equivalent path",
+	Example: `path_clean("a/./b/../c")`,
+}
+
+// NewPathClean returns a new path_clean function.
+// It returns the shortest path equivalent to given file path.
+func NewPathClean() Function { return newValidated(pathCleanSignature, &pathClean{}) }
+
+type pathClean struct{}
+
+func (*pathClean) Name() string { return "path_clean" }
+func (*pathClean) Call(args ...data.Data) (data.Data, error) {
+	return data.FromString(filepath.Clean(args[0].String())), nil
+}
+
+var pathPartSignature = &Signature{
+	Name:    "path_part",
+	Params:  []Param{{Name: "x", Type: TypeString}, {Name: "i", Type: TypeInt}},
+	Returns: TypeString,
+	Doc:     "the i-th element of path, from the last if negative",
+	Example: "path_part(name, -2)",
+}
+
+// NewPathPart returns a new path_part function.
+// It returns the args[1]-th element of args[0], counts from the last element if args[1] is negative.
+// Returns an empty string if out of range.
+func NewPathPart() Function { return newValidated(pathPartSignature, &pathPart{}) }
+
+type pathPart struct{}
+
+func (*pathPart) Name() string { return "path_part" }
+func (*pathPart) Call(args ...data.Data) (data.Data, error) {
+	var (
+		target = args[0]
+		index  = args[1]
+	)
+	parts := []string{}
+	for _, x := range strings.Split(filepath.ToSlash(filepath.Clean(target.String())), "/") {
+		if x != "" {
+			parts = append(parts, x)
+		}
+	}
+	i := index.Int()
+	if i < 0 {
+		i += len(parts)
+	}
+	if i < 0 || i >= len(parts) {
+		return data.FromString(""), nil
+	}
+	return data.FromString(parts[i]), nil
+}
+
+var globMatchSignature = &Signature{
+	Name:    "glob_match",
+	Params:  []Param{{Name: "x", Type: TypeString}, {Name: "y", Type: TypeString}},
+	Returns: TypeBool,
+	Doc:     "x matches the shell pattern y",
+	Example: `glob_match(name, "*.go")`,
+}
+
+// NewGlobMatch returns a new glob_match function.
+// It returns true if args[0] matches the shell file name pattern args[1].
+// The pattern without separators is matched against the last element of the path.
+func NewGlobMatch() Function { return newValidated(globMatchSignature, &globMatch{}) }
+
+type globMatch struct{}
+
+func (*globMatch) Name() string { return "glob_match" }
+func (*globMatch) Call(args ...data.Data) (data.Data, error) {
+	var (
+		target  = args[0]
+		pattern = args[1]
+	)
+	r, err := glob.Match(pattern.String(), target.String())
+	if err != nil {
+		return nil, errors.Wrap(ErrInvalidArgument, "pattern %s %v", pattern.String(), err)
 	}
-	return data.FromString(filepath.Base(arg.String())), nil
+	return data.FromBool(r), nil
+}
+
+var lenSignature = &Signature{
+	Name:    "len",
+	Params:  []Param{{Name: "x", Type: TypeString}},
+	Returns: TypeInt,
+	Doc:     "length of string",
+	Example: `len("length")`,
 }
 
 // NewLen returns a new len function.
 // It returns the length of the string.
-func NewLen() Function { return &length{} }
+func NewLen() Function { return newValidated(lenSignature, &length{}) }
 
 type length struct{}
 
 func (*length) Name() string { return "len" }
 func (*length) Call(args ...data.Data) (data.Data, error) {
-	if len(args) != 1 {
-		return nil, errors.Wrap(ErrInvalidArgument, "arg len want 1 but got %d", len(args))
-	}
-	arg := args[0]
-	if arg.Type() != data.TypeString {
-		return nil, errors.Wrap(ErrInvalidArgument, "arg type want string but got %s", arg.Type())
-	}
-	return data.FromInt(len(arg.String())), nil
+	return data.FromInt(len(args[0].String())), nil
+}
+
+var floorSignature = &Signature{
+	Name:    "floor",
+	Params:  []Param{{Name: "x", Type: TypeNumber}},
+	Returns: TypeInt,
+	Doc:     "floor",
+	Example: "floor(2.3)",
 }
 
 // NewFloor returns a new floor function.
-func NewFloor() Function { return &floor{} }
+func NewFloor() Function { return newValidated(floorSignature, &floor{}) }
 
 type floor struct{}
 
 func (*floor) Name() string { return "floor" }
 func (*floor) Call(args ...data.Data) (data.Data, error) {
-	if len(args) != 1 {
-		return nil, errors.Wrap(ErrInvalidArgument, "arg len want 1 but got %d", len(args))
-	}
 	arg := args[0]
-	switch arg.Type() {
-	case data.TypeInt:
+	if arg.Type() == data.TypeInt {
 		return arg, nil
-	case data.TypeFloat:
-		return data.FromInt(int(math.Floor(arg.Float()))), nil
-	default:
-		return nil, errors.Wrap(ErrInvalidArgument, "arg type want int or float but got %s", arg.Type())
 	}
+	return data.FromInt(int(math.Floor(arg.Float()))), nil
+}
+
+var ceilSignature = &Signature{
+	Name:    "ceil",
+	Params:  []Param{{Name: "x", Type: TypeNumber}},
+	Returns: TypeInt,
+	Doc:     "ceiling",
+	Example: "ceil(2.3)",
 }
 
 // NewCeil returns a new ceil function.
-func NewCeil() Function { return &ceil{} }
+func NewCeil() Function { return newValidated(ceilSignature, &ceil{}) }
 
 type ceil struct{}
 
 func (*ceil) Name() string { return "ceil" }
 func (*ceil) Call(args ...data.Data) (data.Data, error) {
-	if len(args) != 1 {
-		return nil, errors.Wrap(ErrInvalidArgument, "arg len want 1 but got %d", len(args))
-	}
 	arg := args[0]
-	switch arg.Type() {
-	case data.TypeInt:
+	if arg.Type() == data.TypeInt {
 		return arg, nil
-	case data.TypeFloat:
-		return data.FromInt(int(math.Ceil(arg.Float()))), nil
-	default:
-		return nil, errors.Wrap(ErrInvalidArgument, "arg type want int or float but got %s", arg.Type())
 	}
+	return data.FromInt(int(math.Ceil(arg.Float()))), nil
+}
+
+var powSignature = &Signature{
+	Name:    "pow",
+	Params:  []Param{{Name: "x", Type: TypeNumber}, {Name: "y", Type: TypeNumber}},
+	Returns: TypeNumber,
+	Doc:     "x to the power of y",
+	Example: "pow(2, 3)",
 }
 
 // NewPow returns new pow function.
 func NewPow(calculator arithmetic.Calculator) Function {
-	return &pow{
+	return newValidated(powSignature, &pow{
 		calculator: calculator,
-	}
+	})
 }
 
 type pow struct {
@@ -330,9 +561,6 @@ type pow struct {
 
 func (*pow) Name() string { return "pow" }
 func (s *pow) Call(args ...data.Data) (data.Data, error) {
-	if len(args) != 2 {
-		return nil, errors.Wrap(ErrInvalidArgument, "arg len want 2 but got %d", len(args))
-	}
 	r, err := s.calculator.Pow(args[0].Value(), args[1].Value())
 	if err != nil {
 		return nil, errors.Wrap(err, "left %v right %v", args[0].Value(), args[1].Value())
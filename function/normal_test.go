@@ -67,3 +67,129 @@ func TestGrep(t *testing.T) {
 		})
 	}
 }
+
+func TestFunctions(t *testing.T) {
+	s := data.FromString
+	for _, tc := range []*struct {
+		title string
+		f     function.Function
+		args  []data.Data
+		want  data.Data
+		isErr bool
+	}{
+		{
+			title: "stem",
+			f:     function.NewStem(),
+			args:  []data.Data{s("/dir/archive.tar.gz")},
+			want:  s("archive.tar"),
+		},
+		{
+			title: "stem no ext",
+			f:     function.NewStem(),
+			args:  []data.Data{s("/dir/Makefile")},
+			want:  s("Makefile"),
+		},
+		{
+			title: "relpath",
+			f:     function.NewRelPath(),
+			args:  []data.Data{s("/home/user/dir/file"), s("/home/user")},
+			want:  s("dir/file"),
+		},
+		{
+			title: "relpath itself",
+			f:     function.NewRelPath(),
+			args:  []data.Data{s("/home/user"), s("/home/user")},
+			want:  s("."),
+		},
+		{
+			title: "relpath invalid",
+			f:     function.NewRelPath(),
+			args:  []data.Data{s("/home/user")},
+			isErr: true,
+		},
+		{
+			title: "path_join",
+			f:     function.NewPathJoin(),
+			args:  []data.Data{s("dir"), s("sub/"), s("file")},
+			want:  s("dir/sub/file"),
+		},
+		{
+			title: "path_join invalid",
+			f:     function.NewPathJoin(),
+			args:  []data.Data{s("dir"), data.FromInt(1)},
+			isErr: true,
+		},
+		{
+			title: "path_clean",
+			f:     function.NewPathClean(),
+			args:  []data.Data{s("/dir/./sub/../file")},
+			want:  s("/dir/file"),
+		},
+		{
+			title: "path_part",
+			f:     function.NewPathPart(),
+			args:  []data.Data{s("/home/user/file"), data.FromInt(1)},
+			want:  s("user"),
+		},
+		{
+			title: "path_part from last",
+			f:     function.NewPathPart(),
+			args:  []data.Data{s("/home/user/file"), data.FromInt(-1)},
+			want:  s("file"),
+		},
+		{
+			title: "path_part out of range",
+			f:     function.NewPathPart(),
+			args:  []data.Data{s("/home/user/file"), data.FromInt(3)},
+			want:  s(""),
+		},
+		{
+			title: "int2oct",
+			f:     function.NewInt2Oct(),
+			args:  []data.Data{data.FromInt(493)},
+			want:  s("755"),
+		},
+		{
+			title: "int2hex",
+			f:     function.NewInt2Hex(),
+			args:  []data.Data{data.FromInt(511)},
+			want:  s("1ff"),
+		},
+		{
+			title: "int2hex invalid",
+			f:     function.NewInt2Hex(),
+			args:  []data.Data{s("511")},
+			isErr: true,
+		},
+		{
+			title: "glob_match base",
+			f:     function.NewGlobMatch(),
+			args:  []data.Data{s("/dir/main.go"), s("*.go")},
+			want:  data.FromBool(true),
+		},
+		{
+			title: "glob_match path",
+			f:     function.NewGlobMatch(),
+			args:  []data.Data{s("/dir/main.go"), s("/*.go")},
+			want:  data.FromBool(false),
+		},
+		{
+			title: "glob_match invalid pattern",
+			f:     function.NewGlobMatch(),
+			args:  []data.Data{s("/dir/main.go"), s("[")},
+			isErr: true,
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			got, err := tc.f.Call(tc.args...)
+			if tc.isErr {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tc.want.Type(), got.Type())
+			assert.Equal(t, tc.want.Value(), got.Value())
+		})
+	}
+}
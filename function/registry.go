@@ -0,0 +1,89 @@
+package function
+
+// builtin is a builtin function in the registry.
+type builtin struct {
+	sig *Signature
+	new func(s *factoryBuidler) Function
+}
+
+// builtins are the builtin functions in the order of the documents.
+var builtins = []*builtin{
+	{sig: powSignature, new: func(s *factoryBuidler) Function { return NewPow(s.artCalculator) }},
+	{sig: ceilSignature, new: func(_ *factoryBuidler) Function { return NewCeil() }},
+	{sig: floorSignature, new: func(_ *factoryBuidler) Function { return NewFloor() }},
+	{sig: lenSignature, new: func(_ *factoryBuidler) Function { return NewLen() }},
+	{sig: baseSignature, new: func(_ *factoryBuidler) Function { return NewBase() }},
+	{sig: dirSignature, new: func(_ *factoryBuidler) Function { return NewDir() }},
+	{sig: extSignature, new: func(_ *factoryBuidler) Function { return NewExt() }},
+	{sig: bin2intSignature, new: func(_ *factoryBuidler) Function { return NewBin2Int() }},
+	{sig: int2binSignature, new: func(_ *factoryBuidler) Function { return NewInt2Bin() }},
+	{sig: int2octSignature, new: func(_ *factoryBuidler) Function { return NewInt2Oct() }},
+	{sig: int2hexSignature, new: func(_ *factoryBuidler) Function { return NewInt2Hex() }},
+	{sig: castSignature, new: func(s *factoryBuidler) Function { return NewCast(s.caster) }},
+	{sig: nowSignature, new: func(_ *factoryBuidler) Function { return NewNow() }},
+	{sig: depthSignature, new: func(_ *factoryBuidler) Function { return NewDepth() }},
+	{sig: grepSignature, new: func(s *factoryBuidler) Function { return NewGrep(s.grepper) }},
+	{sig: relPathSignature, new: func(_ *factoryBuidler) Function { return NewRelPath() }},
+	{sig: stemSignature, new: func(_ *factoryBuidler) Function { return NewStem() }},
+	{sig: pathJoinSignature, new: func(_ *factoryBuidler) Function { return NewPathJoin() }},
+	{sig: pathCleanSignature, new: func(_ *factoryBuidler) Function { return NewPathClean() }},
+	{sig: pathPartSignature, new: func(_ *factoryBuidler) Function { return NewPathPart() }},
+	{sig: globMatchSignature, new: func(_ *factoryBuidler) Function { return NewGlobMatch() }},
+	{sig: countSignature, new: func(_ *factoryBuidler) Function { return NewCount() }},
+	{sig: minSignature, new: func(s *factoryBuidler) Function { return NewMin(s.comparer) }},
+	{sig: maxSignature, new: func(s *factoryBuidler) Function { return NewMax(s.comparer) }},
+	{sig: productSignature, new: func(s *factoryBuidler) Function { return NewProduct(s.artCalculator) }},
+	{sig: sumSignature, new: func(s *factoryBuidler) Function { return NewSum(s.artCalculator) }},
+	{sig: avgSignature, new: func(s *factoryBuidler) Function {
+		return NewAvg(s.artCalculator, NewSum(s.artCalculator))
+	}},
+}
+
+var builtinIndex = func() map[string]*builtin {
+	d := make(map[string]*builtin, len(builtins))
+	for _, b := range builtins {
+		d[b.sig.Name] = b
+	}
+	return d
+}()
+
+// Signatures returns the signatures of the builtin functions in the order of the documents.
+func Signatures() []*Signature {
+	r := make([]*Signature, len(builtins))
+	for i, b := range builtins {
+		r[i] = b.sig
+	}
+	return r
+}
+
+// LookupSignature returns the signature of the builtin function.
+func LookupSignature(name string) (*Signature, bool) {
+	b, ok := builtinIndex[name]
+	if !ok {
+		return nil, false
+	}
+	return b.sig, true
+}
+
+func NormalFunctionNames() []string { return signatureNames(signatures(false)) }
+
+func AggregationFunctionNames() []string { return signatureNames(signatures(true)) }
+
+// signatures returns the signatures of the normal functions or the aggregations.
+func signatures(aggregation bool) []*Signature {
+	r := []*Signature{}
+	for _, b := range builtins {
+		if b.sig.Aggregation == aggregation {
+			r = append(r, b.sig)
+		}
+	}
+	return r
+}
+
+func signatureNames(signatures []*Signature) []string {
+	r := make([]string, len(signatures))
+	for i, s := range signatures {
+		r[i] = s.Name
+	}
+	return r
+}
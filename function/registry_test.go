@@ -0,0 +1,60 @@
+package function_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/berquerant/dql/arithmetic"
+	"github.com/berquerant/dql/cast"
+	"github.com/berquerant/dql/compare"
+	"github.com/berquerant/dql/data"
+	"github.com/berquerant/dql/function"
+	"github.com/berquerant/gogrep"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry(t *testing.T) {
+	builder := function.NewFactoryBuilder(cast.New(), arithmetic.New(), compare.New(), gogrep.New())
+	for _, sig := range function.Signatures() {
+		sig := sig
+		t.Run(sig.Name, func(t *testing.T) {
+			factory, ok := builder.Factory(sig.Name)
+			if !assert.True(t, ok) {
+				return
+			}
+			f := factory()
+			assert.Equal(t, sig.Name, f.Name())
+			_, isAggregation := f.(function.Aggregation)
+			assert.Equal(t, sig.Aggregation, isAggregation)
+			if !sig.Aggregation {
+				// the arity is validated by the signature
+				n := len(sig.Params) + 1
+				if sig.Variadic {
+					n = len(sig.Params) - 1
+				}
+				args := make([]data.Data, n)
+				for i := range args {
+					args[i] = data.FromBool(true)
+				}
+				_, err := f.Call(args...)
+				assert.ErrorIs(t, err, function.ErrInvalidArgument)
+			}
+		})
+	}
+
+	t.Run("unknown", func(t *testing.T) {
+		_, ok := builder.Factory("unknown")
+		assert.False(t, ok)
+	})
+}
+
+func TestREADME(t *testing.T) {
+	b, err := os.ReadFile("../README.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := string(b)
+	got, err := function.UpdateDoc(doc)
+	assert.Nil(t, err)
+	assert.Equal(t, doc, got, "README.md is outdated, run make readme")
+}
@@ -0,0 +1,252 @@
+package function
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/berquerant/dql/data"
+	"github.com/berquerant/dql/errors"
+)
+
+// Type is a set of the types of the arguments or the results.
+type Type uint8
+
+const (
+	TypeInt    Type = 1 << data.TypeInt
+	TypeFloat  Type = 1 << data.TypeFloat
+	TypeString Type = 1 << data.TypeString
+	TypeBool   Type = 1 << data.TypeBool
+	TypeNumber      = TypeInt | TypeFloat
+	TypeAny         = TypeInt | TypeFloat | TypeString | TypeBool
+	// TypeUnknown means that the type is not known statically.
+	TypeUnknown Type = 0
+)
+
+// TypeOf returns the set of t only.
+func TypeOf(t data.Type) Type { return 1 << t }
+
+// Contains returns true if t is in the set.
+func (s Type) Contains(t data.Type) bool { return s&TypeOf(t) != 0 }
+
+// Types returns the types in the set.
+func (s Type) Types() []data.Type {
+	r := []data.Type{}
+	for _, t := range []data.Type{data.TypeInt, data.TypeFloat, data.TypeString, data.TypeBool} {
+		if s.Contains(t) {
+			r = append(r, t)
+		}
+	}
+	return r
+}
+
+// IsSubsetOf returns true if the types are known and all of them are in x.
+func (s Type) IsSubsetOf(x Type) bool { return s != TypeUnknown && s&^x == 0 }
+
+// IsDisjoint returns true if the types are known and none of them are in x.
+func (s Type) IsDisjoint(x Type) bool { return s != TypeUnknown && x != TypeUnknown && s&x == 0 }
+
+func (s Type) String() string {
+	switch s {
+	case TypeUnknown:
+		return "unknown"
+	case TypeNumber:
+		return "number"
+	case TypeAny:
+		return "any"
+	}
+	ts := s.Types()
+	xs := make([]string, len(ts))
+	for i, t := range ts {
+		xs[i] = TypeName(t)
+	}
+	return strings.Join(xs, " or ")
+}
+
+func (s Type) MarshalJSON() ([]byte, error) { return json.Marshal(s.Types()) }
+
+// TypeName returns the name of the type in the queries.
+func TypeName(t data.Type) string { return strings.ToLower(strings.TrimPrefix(t.String(), "Type")) }
+
+type (
+	// Signature declares the arguments and the result of a builtin function.
+	Signature struct {
+		Name   string  `json:"name"`
+		Params []Param `json:"params"`
+		// Variadic is true if the last parameter can be repeated.
+		Variadic bool `json:"variadic"`
+		// Returns is the type of the result.
+		Returns Type `json:"returns"`
+		// ReturnKind is the name of the result type in the documents, e.g. bits.
+		// Empty if it is the name of Returns.
+		ReturnKind string `json:"return_kind,omitempty"`
+		// ResultOf narrows Returns by the arguments of a call if not nil.
+		ResultOf func(args []Arg) Type `json:"-"`
+		// NonNullable is true if the result is available even if the arguments are unavailable.
+		NonNullable bool `json:"non_nullable"`
+		// Aggregation is true if the function aggregates the rows.
+		// The function is called with the values of the rows, each of the type of the parameter.
+		Aggregation bool   `json:"aggregation"`
+		Doc         string `json:"doc"`
+		Example     string `json:"example"`
+	}
+
+	// Param is a parameter of a function.
+	Param struct {
+		Name string `json:"name"`
+		Type Type   `json:"type"`
+		// Kind is the name of the type in the documents, e.g. bits.
+		// Empty if it is the name of Type.
+		Kind string `json:"kind,omitempty"`
+	}
+
+	// Arg is an argument of a call for the type inference.
+	Arg struct {
+		Type Type
+		// Lit is the value if the argument is a literal, otherwise nil.
+		Lit data.Data
+	}
+)
+
+// Param returns the parameter for the i-th argument.
+func (s *Signature) Param(i int) Param {
+	if i < len(s.Params) {
+		return s.Params[i]
+	}
+	return s.Params[len(s.Params)-1]
+}
+
+// Arity returns the number of the arguments in the documents.
+func (s *Signature) Arity() string {
+	if s.Variadic {
+		return fmt.Sprintf("%d or more", len(s.Params))
+	}
+	return fmt.Sprint(len(s.Params))
+}
+
+// AcceptsArity returns true if the function accepts n arguments.
+func (s *Signature) AcceptsArity(n int) bool {
+	return n == len(s.Params) || (s.Variadic && n >= len(s.Params))
+}
+
+// Result returns the type of the result of the call with args.
+func (s *Signature) Result(args []Arg) Type {
+	if s.ResultOf == nil {
+		return s.Returns
+	}
+	return s.ResultOf(args)
+}
+
+// Format returns the call with the names of the parameters, e.g. len(x).
+func (s *Signature) Format() string {
+	xs := make([]string, len(s.Params))
+	for i, p := range s.Params {
+		xs[i] = p.Name
+	}
+	if s.Variadic {
+		xs = append(xs, "...")
+	}
+	return fmt.Sprintf("%s(%s)", s.Name, strings.Join(xs, ", "))
+}
+
+// ParamKinds returns the names of the types of the parameters in the documents.
+func (s *Signature) ParamKinds() string {
+	xs := make([]string, len(s.Params))
+	for i, p := range s.Params {
+		xs[i] = p.kind()
+	}
+	if s.Variadic {
+		xs = append(xs, "...")
+	}
+	return strings.Join(xs, ", ")
+}
+
+// ResultKind returns the name of the result type in the documents.
+func (s *Signature) ResultKind() string {
+	if s.ReturnKind != "" {
+		return s.ReturnKind
+	}
+	return s.Returns.String()
+}
+
+func (s Param) kind() string {
+	if s.Kind != "" {
+		return s.Kind
+	}
+	return s.Type.String()
+}
+
+// Validate returns an error wrapping ErrInvalidArgument if args do not match the signature.
+func (s *Signature) Validate(args []data.Data) error {
+	if s.Aggregation {
+		p := s.Params[0]
+		for i, a := range args {
+			if !p.Type.Contains(a.Type()) {
+				return errors.Wrap(ErrInvalidArgument, "arg[%d] type want %s but got %s", i, p.kind(), a.Type())
+			}
+		}
+		return nil
+	}
+	if !s.AcceptsArity(len(args)) {
+		return errors.Wrap(ErrInvalidArgument, "arg len want %s but got %d", s.Arity(), len(args))
+	}
+	for i, a := range args {
+		if p := s.Param(i); !p.Type.Contains(a.Type()) {
+			return errors.Wrap(ErrInvalidArgument, "arg[%d] type want %s but got %s", i, p.kind(), a.Type())
+		}
+	}
+	return nil
+}
+
+// SameAsArg returns the type of the first argument.
+func SameAsArg(args []Arg) Type {
+	if len(args) == 0 {
+		return TypeUnknown
+	}
+	return args[0].Type
+}
+
+// IntIfInt returns int if the first argument is an int, otherwise number.
+func IntIfInt(args []Arg) Type {
+	if len(args) > 0 && args[0].Type.IsSubsetOf(TypeInt) {
+		return TypeInt
+	}
+	return TypeNumber
+}
+
+// validated calls the function after validating the arguments by the signature.
+type validated struct {
+	sig *Signature
+	f   Function
+}
+
+// newValidated returns the function that validates the arguments by sig before calling f.
+func newValidated(sig *Signature, f Function) Function {
+	return &validated{
+		sig: sig,
+		f:   f,
+	}
+}
+
+func (s *validated) Name() string { return s.f.Name() }
+func (s *validated) Call(args ...data.Data) (data.Data, error) {
+	if err := s.sig.Validate(args); err != nil {
+		return nil, err
+	}
+	return s.f.Call(args...)
+}
+
+type validatedAggregation struct {
+	*validated
+}
+
+func newValidatedAggregation(sig *Signature, f Aggregation) Aggregation {
+	return &validatedAggregation{
+		validated: &validated{
+			sig: sig,
+			f:   f,
+		},
+	}
+}
+
+func (*validatedAggregation) IsAggregation() {}
@@ -0,0 +1,152 @@
+package function_test
+
+import (
+	"testing"
+
+	"github.com/berquerant/dql/data"
+	"github.com/berquerant/dql/function"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignatureValidate(t *testing.T) {
+	var (
+		i = data.FromInt
+		s = data.FromString
+	)
+
+	for _, tc := range []*struct {
+		title string
+		name  string
+		args  []data.Data
+		isErr bool
+	}{
+		{
+			title: "valid",
+			name:  "path_part",
+			args:  []data.Data{s("a/b"), i(1)},
+		},
+		{
+			title: "too few args",
+			name:  "path_part",
+			args:  []data.Data{s("a/b")},
+			isErr: true,
+		},
+		{
+			title: "too many args",
+			name:  "now",
+			args:  []data.Data{i(1)},
+			isErr: true,
+		},
+		{
+			title: "invalid type",
+			name:  "path_part",
+			args:  []data.Data{s("a/b"), s("1")},
+			isErr: true,
+		},
+		{
+			title: "number",
+			name:  "floor",
+			args:  []data.Data{data.FromFloat(1.5)},
+		},
+		{
+			title: "variadic",
+			name:  "path_join",
+			args:  []data.Data{s("a"), s("b"), s("c")},
+		},
+		{
+			title: "variadic invalid type",
+			name:  "path_join",
+			args:  []data.Data{s("a"), s("b"), i(1)},
+			isErr: true,
+		},
+		{
+			title: "variadic no args",
+			name:  "path_join",
+			isErr: true,
+		},
+		{
+			title: "aggregation",
+			name:  "sum",
+			args:  []data.Data{i(1), data.FromFloat(1.5), i(2)},
+		},
+		{
+			title: "aggregation no rows",
+			name:  "count",
+		},
+		{
+			title: "aggregation invalid type",
+			name:  "sum",
+			args:  []data.Data{i(1), s("2")},
+			isErr: true,
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			sig, ok := function.LookupSignature(tc.name)
+			if !assert.True(t, ok) {
+				return
+			}
+			err := sig.Validate(tc.args)
+			if tc.isErr {
+				assert.ErrorIs(t, err, function.ErrInvalidArgument)
+				return
+			}
+			assert.Nil(t, err)
+		})
+	}
+}
+
+func TestSignatureResult(t *testing.T) {
+	for _, tc := range []*struct {
+		title string
+		name  string
+		args  []function.Arg
+		want  function.Type
+	}{
+		{
+			title: "returns",
+			name:  "len",
+			args:  []function.Arg{{Type: function.TypeString}},
+			want:  function.TypeInt,
+		},
+		{
+			title: "same as arg",
+			name:  "max",
+			args:  []function.Arg{{Type: function.TypeString}},
+			want:  function.TypeString,
+		},
+		{
+			title: "sum of ints",
+			name:  "sum",
+			args:  []function.Arg{{Type: function.TypeInt}},
+			want:  function.TypeInt,
+		},
+		{
+			title: "sum of numbers",
+			name:  "sum",
+			args:  []function.Arg{{Type: function.TypeNumber}},
+			want:  function.TypeNumber,
+		},
+		{
+			title: "cast literal",
+			name:  "cast",
+			args:  []function.Arg{{Type: function.TypeInt}, {Type: function.TypeString, Lit: data.FromString("Float")}},
+			want:  function.TypeFloat,
+		},
+		{
+			title: "cast not literal",
+			name:  "cast",
+			args:  []function.Arg{{Type: function.TypeInt}, {Type: function.TypeString}},
+			want:  function.TypeUnknown,
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			sig, ok := function.LookupSignature(tc.name)
+			if !assert.True(t, ok) {
+				return
+			}
+			assert.Equal(t, tc.want, sig.Result(tc.args))
+		})
+	}
+}
@@ -0,0 +1,16 @@
+package glob
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// Match reports whether name matches the shell file name pattern.
+// If the pattern contains no separators, it is matched against the last element of name,
+// otherwise against the whole name.
+func Match(pattern, name string) (bool, error) {
+	if !strings.ContainsRune(pattern, filepath.Separator) {
+		name = filepath.Base(name)
+	}
+	return filepath.Match(pattern, name)
+}
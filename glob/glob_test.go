@@ -0,0 +1,58 @@
+package glob_test
+
+import (
+	"testing"
+
+	"github.com/berquerant/dql/glob"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatch(t *testing.T) {
+	for _, tc := range []*struct {
+		title   string
+		pattern string
+		name    string
+		want    bool
+		isErr   bool
+	}{
+		{
+			title:   "base matched",
+			pattern: "*.go",
+			name:    "/home/user/main.go",
+			want:    true,
+		},
+		{
+			title:   "base not matched",
+			pattern: "*.go",
+			name:    "/home/user/main.go.bak",
+		},
+		{
+			title:   "path matched",
+			pattern: "/home/*/main.go",
+			name:    "/home/user/main.go",
+			want:    true,
+		},
+		{
+			title:   "path not matched",
+			pattern: "/home/*.go",
+			name:    "/home/user/main.go",
+		},
+		{
+			title:   "bad pattern",
+			pattern: "[",
+			name:    "main.go",
+			isErr:   true,
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			got, err := glob.Match(tc.pattern, tc.name)
+			if tc.isErr {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
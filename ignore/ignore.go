@@ -0,0 +1,266 @@
+package ignore
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/berquerant/dql/errors"
+)
+
+// Matcher decides whether the file is ignored or not by gitignore patterns.
+type Matcher interface {
+	// Match returns true as matched if the file is matched with any pattern.
+	// ignored is false if the last matched pattern is a negation.
+	// name is the path of the file.
+	Match(name string, isDir bool) (ignored, matched bool)
+}
+
+// New returns a new Matcher from the lines of an ignore file in dir.
+// The patterns are relative to dir.
+func New(dir string, lines []string) Matcher {
+	ps := []*pattern{}
+	for _, line := range lines {
+		if p, ok := parsePattern(line); ok {
+			ps = append(ps, p)
+		}
+	}
+	return &matcher{
+		dir:      dir,
+		patterns: ps,
+	}
+}
+
+// Load reads the ignore files in dir and returns a Matcher.
+// files are the paths relative to dir, the later file has the higher priority.
+// The files that do not exist are ignored.
+// Returns nil if no files exist.
+func Load(dir string, files ...string) (Matcher, error) {
+	var m Matcher
+	for _, f := range files {
+		lines, err := readLines(filepath.Join(dir, f))
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return nil, errors.Wrap(err, "ignore load %s", f)
+		}
+		m = Chain(m, New(dir, lines))
+	}
+	return m, nil
+}
+
+func readLines(name string) ([]string, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var (
+		lines   = []string{}
+		scanner = bufio.NewScanner(f)
+	)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	return lines, scanner.Err()
+}
+
+// Chain returns a new Matcher that tries child and then parent.
+// parent and child can be nil.
+func Chain(parent, child Matcher) Matcher {
+	switch {
+	case parent == nil:
+		return child
+	case child == nil:
+		return parent
+	default:
+		return &chain{
+			parent: parent,
+			child:  child,
+		}
+	}
+}
+
+type chain struct {
+	parent Matcher
+	child  Matcher
+}
+
+func (s *chain) Match(name string, isDir bool) (bool, bool) {
+	if ignored, matched := s.child.Match(name, isDir); matched {
+		return ignored, true
+	}
+	return s.parent.Match(name, isDir)
+}
+
+type matcher struct {
+	dir      string
+	patterns []*pattern
+}
+
+func (s *matcher) Match(name string, isDir bool) (bool, bool) {
+	rel, err := filepath.Rel(s.dir, name)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return false, false
+	}
+	rel = filepath.ToSlash(rel)
+	for i := len(s.patterns) - 1; i >= 0; i-- {
+		if p := s.patterns[i]; p.match(rel, isDir) {
+			return !p.negate, true
+		}
+	}
+	return false, false
+}
+
+type pattern struct {
+	re       *regexp.Regexp
+	negate   bool
+	dirOnly  bool
+	anchored bool
+}
+
+func (s *pattern) match(rel string, isDir bool) bool {
+	if s.dirOnly && !isDir {
+		return false
+	}
+	if !s.anchored {
+		rel = path.Base(rel)
+	}
+	return s.re.MatchString(rel)
+}
+
+// parsePattern parses a line of an ignore file.
+// Returns false if the line is blank or a comment.
+func parsePattern(line string) (*pattern, bool) {
+	line = strings.TrimSuffix(line, "\r")
+	// trailing spaces are ignored unless they are quoted with backslash
+	for strings.HasSuffix(line, " ") && !strings.HasSuffix(line, `\ `) {
+		line = line[:len(line)-1]
+	}
+	if line == "" || strings.HasPrefix(line, "#") {
+		return nil, false
+	}
+	p := &pattern{}
+	switch {
+	case strings.HasPrefix(line, "!"):
+		p.negate = true
+		line = line[1:]
+	case strings.HasPrefix(line, `\!`), strings.HasPrefix(line, `\#`):
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		p.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if line == "" {
+		return nil, false
+	}
+	// the pattern with a separator at the beginning or middle is relative to the directory of the ignore file
+	p.anchored = strings.Contains(line, "/")
+	line = strings.TrimPrefix(line, "/")
+	re, err := regexp.Compile("^" + translate(line) + "$")
+	if err != nil {
+		return nil, false
+	}
+	p.re = re
+	return p, true
+}
+
+// translate converts a gitignore glob into a regular expression.
+func translate(glob string) string {
+	var (
+		b = &strings.Builder{}
+		r = []rune(glob)
+		n = len(r)
+	)
+	for i := 0; i < n; i++ {
+		switch c := r[i]; c {
+		case '*':
+			if i+1 < n && r[i+1] == '*' && (i == 0 || r[i-1] == '/') && (i+2 == n || r[i+2] == '/') {
+				if i+2 == n {
+					// trailing **, matches everything inside
+					b.WriteString(".*")
+					i++
+					continue
+				}
+				// **/, matches zero or more directories
+				b.WriteString("(?:.*/)?")
+				i += 2
+				continue
+			}
+			for i+1 < n && r[i+1] == '*' {
+				i++
+			}
+			b.WriteString("[^/]*")
+		case '?':
+			b.WriteString("[^/]")
+		case '[':
+			if j := classEnd(r, i); j > 0 {
+				b.WriteString(translateClass(r[i+1 : j]))
+				i = j
+				continue
+			}
+			b.WriteString(`\[`)
+		case '\\':
+			if i+1 < n {
+				i++
+				b.WriteString(regexp.QuoteMeta(string(r[i])))
+				continue
+			}
+			b.WriteString(`\\`)
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return b.String()
+}
+
+// classEnd returns the index of ] that closes the bracket expression starting at i.
+// Returns -1 if not closed.
+func classEnd(r []rune, i int) int {
+	j := i + 1
+	if j < len(r) && (r[j] == '!' || r[j] == '^') {
+		j++
+	}
+	if j < len(r) && r[j] == ']' {
+		// ] at the beginning is a literal
+		j++
+	}
+	for ; j < len(r); j++ {
+		switch r[j] {
+		case '\\':
+			j++
+		case ']':
+			return j
+		}
+	}
+	return -1
+}
+
+func translateClass(r []rune) string {
+	b := &strings.Builder{}
+	b.WriteRune('[')
+	if len(r) > 0 && (r[0] == '!' || r[0] == '^') {
+		b.WriteRune('^')
+		r = r[1:]
+	}
+	for i := 0; i < len(r); i++ {
+		switch c := r[i]; c {
+		case '-':
+			b.WriteRune(c)
+		case '\\':
+			if i+1 < len(r) {
+				i++
+				b.WriteString(regexp.QuoteMeta(string(r[i])))
+			}
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteRune(']')
+	return b.String()
+}
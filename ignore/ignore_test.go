@@ -0,0 +1,174 @@
+package ignore_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/berquerant/dql/ignore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatcher(t *testing.T) {
+	type query struct {
+		name    string
+		isDir   bool
+		ignored bool
+		matched bool
+	}
+	for _, tc := range []*struct {
+		title string
+		lines []string
+		want  []query
+	}{
+		{
+			title: "blank and comment",
+			lines: []string{"", "# a", "   "},
+			want: []query{
+				{name: "/r/a"},
+				{name: "/r/# a"},
+			},
+		},
+		{
+			title: "base name",
+			lines: []string{"*.log"},
+			want: []query{
+				{name: "/r/a.log", ignored: true, matched: true},
+				{name: "/r/sub/dir/a.log", ignored: true, matched: true},
+				{name: "/r/a.log.txt"},
+				{name: "/other/a.log"},
+			},
+		},
+		{
+			title: "anchored",
+			lines: []string{"/build", "doc/*.html"},
+			want: []query{
+				{name: "/r/build", isDir: true, ignored: true, matched: true},
+				{name: "/r/sub/build", isDir: true},
+				{name: "/r/doc/a.html", ignored: true, matched: true},
+				{name: "/r/doc/sub/a.html"},
+				{name: "/r/sub/doc/a.html"},
+			},
+		},
+		{
+			title: "directory only",
+			lines: []string{"out/"},
+			want: []query{
+				{name: "/r/out", isDir: true, ignored: true, matched: true},
+				{name: "/r/sub/out", isDir: true, ignored: true, matched: true},
+				{name: "/r/out"},
+			},
+		},
+		{
+			title: "negation",
+			lines: []string{"*.log", "!keep.log"},
+			want: []query{
+				{name: "/r/a.log", ignored: true, matched: true},
+				{name: "/r/keep.log", matched: true},
+			},
+		},
+		{
+			title: "negation overwritten",
+			lines: []string{"!keep.log", "*.log"},
+			want: []query{
+				{name: "/r/keep.log", ignored: true, matched: true},
+			},
+		},
+		{
+			title: "double asterisk",
+			lines: []string{"**/logs", "a/**/b", "tmp/**"},
+			want: []query{
+				{name: "/r/logs", isDir: true, ignored: true, matched: true},
+				{name: "/r/x/y/logs", isDir: true, ignored: true, matched: true},
+				{name: "/r/a/b", ignored: true, matched: true},
+				{name: "/r/a/x/y/b", ignored: true, matched: true},
+				{name: "/r/tmp/x/y", ignored: true, matched: true},
+				{name: "/r/tmp", isDir: true},
+			},
+		},
+		{
+			title: "wildcards",
+			lines: []string{"a?c", "[0-9].txt", "[!x]y", "*.go/"},
+			want: []query{
+				{name: "/r/abc", ignored: true, matched: true},
+				{name: "/r/ac"},
+				{name: "/r/1.txt", ignored: true, matched: true},
+				{name: "/r/a.txt"},
+				{name: "/r/zy", ignored: true, matched: true},
+				{name: "/r/xy"},
+				{name: "/r/x.go", isDir: true, ignored: true, matched: true},
+			},
+		},
+		{
+			title: "escape",
+			lines: []string{`\!important`, `\#hash`, `trailing\ `, `a\*`},
+			want: []query{
+				{name: "/r/!important", ignored: true, matched: true},
+				{name: "/r/#hash", ignored: true, matched: true},
+				{name: "/r/trailing ", ignored: true, matched: true},
+				{name: "/r/a*", ignored: true, matched: true},
+				{name: "/r/ab"},
+			},
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			m := ignore.New("/r", tc.lines)
+			for _, q := range tc.want {
+				ignored, matched := m.Match(q.name, q.isDir)
+				assert.Equal(t, q.ignored, ignored, "ignored %s", q.name)
+				assert.Equal(t, q.matched, matched, "matched %s", q.name)
+			}
+		})
+	}
+}
+
+func TestChain(t *testing.T) {
+	m := ignore.Chain(
+		ignore.New("/r", []string{"*.log", "/tmp"}),
+		ignore.New("/r/sub", []string{"!keep.log", "/tmp"}),
+	)
+	for _, tc := range []*struct {
+		name    string
+		ignored bool
+	}{
+		{name: "/r/a.log", ignored: true},
+		{name: "/r/keep.log", ignored: true},
+		{name: "/r/sub/a.log", ignored: true},
+		{name: "/r/sub/keep.log"},
+		{name: "/r/tmp", ignored: true},
+		{name: "/r/sub/tmp", ignored: true},
+		{name: "/r/sub/x/tmp"},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			got, _ := m.Match(tc.name, false)
+			assert.Equal(t, tc.ignored, got)
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, ".gitignore"), []byte("*.log\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".ignore"), []byte("!keep.log\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("no files", func(t *testing.T) {
+		m, err := ignore.Load(dir, "not_exist")
+		assert.Nil(t, err)
+		assert.Nil(t, m)
+	})
+
+	t.Run("priority", func(t *testing.T) {
+		m, err := ignore.Load(dir, "not_exist", ".gitignore", ".ignore")
+		assert.Nil(t, err)
+		ignored, _ := m.Match(filepath.Join(dir, "a.log"), false)
+		assert.True(t, ignored)
+		ignored, _ = m.Match(filepath.Join(dir, "keep.log"), false)
+		assert.False(t, ignored)
+	})
+}
@@ -0,0 +1,62 @@
+package dql
+
+import (
+	"github.com/berquerant/dql/dig"
+	"github.com/berquerant/dql/function"
+)
+
+type config struct {
+	digOptions   []dig.Option
+	functions    []function.Function
+	memoryBudget int
+	tempDir      string
+	workers      int
+}
+
+func newConfig() *config {
+	return &config{
+		workers: 1,
+	}
+}
+
+// Option is an option of Compile.
+type Option func(*config)
+
+// WithDigOptions sets the options of the digger reading the files.
+func WithDigOptions(opt ...dig.Option) Option {
+	return func(c *config) {
+		c.digOptions = append(c.digOptions, opt...)
+	}
+}
+
+// WithFunctions adds the functions available in the query.
+// They override the builtin functions of the same names.
+// They should be safe for concurrent use with WithWorkers.
+func WithFunctions(functions ...function.Function) Option {
+	return func(c *config) {
+		c.functions = append(c.functions, functions...)
+	}
+}
+
+// WithMemoryBudget writes the rows to sort or group to temporary files
+// when they exceed approximately n bytes.
+// No limit if n is not positive.
+func WithMemoryBudget(n int) Option {
+	return func(c *config) {
+		c.memoryBudget = n
+	}
+}
+
+// WithTempDir sets the directory to create temporary files, os.TempDir by default.
+func WithTempDir(dir string) Option {
+	return func(c *config) {
+		c.tempDir = dir
+	}
+}
+
+// WithWorkers evaluates the rows of where and select with n goroutines concurrently.
+func WithWorkers(n int) Option {
+	return func(c *config) {
+		c.workers = n
+	}
+}
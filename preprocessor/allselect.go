@@ -13,12 +13,20 @@ var (
 type (
 	selectAll struct {
 		allSymbol string
+		targets   []string
 	}
 )
 
 func NewSelectAll(allSymbol string) PreProcessor {
+	return NewSelectGroup(allSymbol, "name", "size", "mode", "mod_time", "is_dir")
+}
+
+// NewSelectGroup returns a new PreProcessor
+// that expands symbol in select section into targets.
+func NewSelectGroup(symbol string, targets ...string) PreProcessor {
 	return &selectAll{
-		allSymbol: allSymbol,
+		allSymbol: symbol,
+		targets:   targets,
 	}
 }
 
@@ -42,12 +50,11 @@ func (s *selectAll) PreProcess(stmt *ast.Statement) error {
 }
 
 func (s *selectAll) unzip() []*ast.SelectTerm {
-	targets := []string{"name", "size", "mode", "mod_time", "is_dir"}
-	terms := make([]*ast.SelectTerm, len(targets))
-	for i, t := range targets {
+	terms := make([]*ast.SelectTerm, len(s.targets))
+	for i, t := range s.targets {
 		terms[i] = &ast.SelectTerm{
 			Target: &ast.SelectTarget{
-				Expr: s.identExpr(t),
+				Expr: identExpr(t),
 			},
 		}
 	}
@@ -73,7 +80,7 @@ func (*selectAll) fetchIdent(expr ast.Expr) (string, bool) {
 	return "", false
 }
 
-func (*selectAll) identExpr(value string) ast.Expr {
+func identExpr(value string) ast.Expr {
 	return &ast.BoolPrimaryPredicate{
 		Pred: &ast.PredicateBitExpr{
 			Expr: &ast.BitExprSimpleExpr{
@@ -56,3 +56,12 @@ func TestSelectAll(t *testing.T) {
 		})
 	}
 }
+
+func TestSelectGroup(t *testing.T) {
+	lexer := cc.NewLexer(strings.NewReader("select name, stat;"))
+	_ = cc.Parse(lexer)
+	assert.Nil(t, lexer.Err())
+	tree := lexer.Result().(*ast.Statement)
+	assert.Nil(t, preprocessor.NewSelectGroup("stat", "inode", "nlink").PreProcess(tree))
+	assert.Equal(t, "select name, inode, nlink;", tree.String())
+}
@@ -0,0 +1,72 @@
+package preprocessor
+
+import (
+	"github.com/berquerant/dql/ast"
+)
+
+type (
+	defaultArgument struct {
+		functionName string
+		position     int
+		ident        string
+	}
+)
+
+// NewDefaultArgument returns a new PreProcessor
+// that appends ident to the arguments of the function call
+// if the call has just position arguments.
+func NewDefaultArgument(functionName string, position int, ident string) PreProcessor {
+	return &defaultArgument{
+		functionName: functionName,
+		position:     position,
+		ident:        ident,
+	}
+}
+
+func (s *defaultArgument) PreProcess(stmt *ast.Statement) error {
+	for _, expr := range s.exprs(stmt) {
+		expr.Accept(ast.NewBaseVisitor(s.complete))
+	}
+	return nil
+}
+
+func (s *defaultArgument) complete(expr ast.Expr) bool {
+	f, ok := expr.(*ast.FunctionCall)
+	if !ok || f.FunctionName.Value != s.functionName {
+		return true
+	}
+	if f.Arguments == nil {
+		f.Arguments = &ast.Exprs{}
+	}
+	if len(f.Arguments.Exprs) == s.position {
+		f.Arguments.Exprs = append(f.Arguments.Exprs, identExpr(s.ident))
+	}
+	return true
+}
+
+func (*defaultArgument) exprs(stmt *ast.Statement) []ast.Expr {
+	exprs := []ast.Expr{}
+	for _, t := range stmt.SelectSection.Terms.Terms {
+		exprs = append(exprs, t.Target.Expr)
+	}
+	if stmt.PruneSection != nil {
+		exprs = append(exprs, stmt.PruneSection.Condition.Expr)
+	}
+	if stmt.WhereSection != nil {
+		exprs = append(exprs, stmt.WhereSection.Condition.Expr)
+	}
+	if stmt.GroupBySection != nil {
+		for _, t := range stmt.GroupBySection.Terms.Terms {
+			exprs = append(exprs, t.Expr)
+		}
+	}
+	if stmt.HavingSection != nil {
+		exprs = append(exprs, stmt.HavingSection.Condition.Expr)
+	}
+	if stmt.OrderBySection != nil {
+		for _, t := range stmt.OrderBySection.Terms.Terms {
+			exprs = append(exprs, t.Expr)
+		}
+	}
+	return exprs
+}
@@ -0,0 +1,55 @@
+package preprocessor_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/berquerant/dql/ast"
+	"github.com/berquerant/dql/cc"
+	"github.com/berquerant/dql/preprocessor"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultArgument(t *testing.T) {
+	for _, tc := range []*struct {
+		title string
+		input string
+		want  string
+	}{
+		{
+			title: "no calls",
+			input: "select name;",
+			want:  "select name;",
+		},
+		{
+			title: "complete",
+			input: "select relpath(name);",
+			want:  "select relpath(name, root);",
+		},
+		{
+			title: "already given",
+			input: "select relpath(name, dir(name));",
+			want:  "select relpath(name, dir(name));",
+		},
+		{
+			title: "nested",
+			input: "select len(relpath(name)) where relpath(name) <> \".\" order by relpath(name);",
+			want:  "select len(relpath(name, root)) where relpath(name, root) <> \".\" order by relpath(name, root);",
+		},
+		{
+			title: "other function",
+			input: "select base(name);",
+			want:  "select base(name);",
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			lexer := cc.NewLexer(strings.NewReader(tc.input))
+			_ = cc.Parse(lexer)
+			assert.Nil(t, lexer.Err())
+			tree := lexer.Result().(*ast.Statement)
+			assert.Nil(t, preprocessor.NewDefaultArgument("relpath", 1, "root").PreProcess(tree))
+			assert.Equal(t, tc.want, tree.String())
+		})
+	}
+}